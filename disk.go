@@ -0,0 +1,423 @@
+package lru
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// diskSegmentName 是磁盘缓存段文件的文件名
+	diskSegmentName = "cache.seg"
+	// diskHeaderSize 是每条记录的头部长度：4字节负载长度 + 4字节CRC32校验和
+	diskHeaderSize = 8
+	// diskCompactMinGarbage 是触发自动压缩的最小垃圾字节数
+	diskCompactMinGarbage = 64 << 10
+)
+
+// DiskTier 是基于磁盘的缓存层，可作为TieredCache的L2接收L1淘汰的项
+// 数据以追加方式写入目录中的段文件，内存中只保存索引；
+// 垃圾数据超过有效数据时自动压缩，总大小超过字节预算时丢弃最早写入的项
+type DiskTier[K comparable, V any] struct {
+	mu       sync.Mutex
+	dir      string              // 段文件所在目录
+	file     *os.File            // 当前段文件
+	size     int64               // 段文件大小，即下一条记录的写入位置
+	live     int64               // 有效记录占用的字节数
+	maxBytes int64               // 有效记录的字节预算
+	index    map[K]*list.Element // 键到索引项的映射
+	order    *list.List          // 按写入顺序排列的索引项，头部为最新写入
+	orphans  map[K]struct{}      // 已从索引丢弃但段文件中可能仍有有效记录的键，删除时需要追加删除记录
+}
+
+// diskEntry 是磁盘缓存的内存索引项
+type diskEntry[K comparable] struct {
+	key      K         // 缓存项的键
+	offset   int64     // 记录在段文件中的偏移
+	length   int64     // 记录的总长度（包含头部）
+	expireAt time.Time // 过期时间点，零值表示永不过期
+}
+
+// diskRecord 是写入段文件的记录
+type diskRecord[K comparable, V any] struct {
+	Key      K
+	Value    V
+	ExpireAt time.Time
+	Deleted  bool // 删除标记，用于在重新打开时忽略已删除的键
+}
+
+// OpenDiskTier 打开或创建目录中的磁盘缓存
+// 参数 dir: 段文件所在目录，不存在时会创建
+// 参数 maxBytes: 有效记录的字节预算，小于等于0时不限制
+// 返回值: 磁盘缓存实例和错误，已有段文件会被扫描以重建索引，末尾损坏的记录会被截断
+func OpenDiskTier[K comparable, V any](dir string, maxBytes int64) (*DiskTier[K, V], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, diskSegmentName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiskTier[K, V]{
+		dir:      dir,
+		file:     file,
+		maxBytes: maxBytes,
+		index:    make(map[K]*list.Element),
+		order:    list.New(),
+		orphans:  make(map[K]struct{}),
+	}
+	if err := d.scan(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	d.enforceBudget()
+	return d, nil
+}
+
+// Fetch 获取键对应的值及其过期时间点
+// 已过期的项会从索引中删除
+func (d *DiskTier[K, V]) Fetch(key K) (V, time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var zero V
+	e, ok := d.index[key]
+	if !ok {
+		return zero, time.Time{}, false
+	}
+
+	item := e.Value.(diskEntry[K])
+	if !item.expireAt.IsZero() && !time.Now().Before(item.expireAt) {
+		d.drop(e)
+		return zero, time.Time{}, false
+	}
+
+	rec, err := d.read(item.offset, item.length)
+	if err != nil {
+		d.drop(e)
+		d.orphans[key] = struct{}{}
+		return zero, time.Time{}, false
+	}
+	return rec.Value, rec.ExpireAt, true
+}
+
+// Put 追加写入键值对及其过期时间点
+func (d *DiskTier[K, V]) Put(key K, value V, expireAt time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !expireAt.IsZero() && !time.Now().Before(expireAt) {
+		return nil
+	}
+
+	offset, length, err := d.append(diskRecord[K, V]{Key: key, Value: value, ExpireAt: expireAt})
+	if err != nil {
+		return err
+	}
+
+	if e, ok := d.index[key]; ok {
+		d.drop(e)
+	}
+	delete(d.orphans, key)
+	d.index[key] = d.order.PushFront(diskEntry[K]{key, offset, length, expireAt})
+	d.live += length
+
+	d.enforceBudget()
+	return d.maybeCompact()
+}
+
+// Remove 删除键，会追加一条删除记录，保证重新打开后不会恢复该键
+// 键不在索引中时只在段文件中可能仍有其有效记录（因超出预算或读取失败被丢弃）时追加删除记录，
+// 删除从未写入的键不会写入磁盘
+func (d *DiskTier[K, V]) Remove(key K) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.index[key]
+	if ok {
+		d.drop(e)
+	} else if _, orphan := d.orphans[key]; !orphan {
+		return false, nil
+	}
+	delete(d.orphans, key)
+
+	if _, _, err := d.append(diskRecord[K, V]{Key: key, Deleted: true}); err != nil {
+		return ok, err
+	}
+	return ok, d.maybeCompact()
+}
+
+// Clear 删除所有键并清空段文件
+func (d *DiskTier[K, V]) Clear() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.index = make(map[K]*list.Element)
+	d.orphans = make(map[K]struct{})
+	d.order.Init()
+	d.live = 0
+	d.size = 0
+	return d.file.Truncate(0)
+}
+
+// Len 返回索引中的项数，可能包含尚未被删除的过期项
+func (d *DiskTier[K, V]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.order.Len()
+}
+
+// Bytes 返回段文件的大小
+func (d *DiskTier[K, V]) Bytes() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.size
+}
+
+// Compact 将有效且未过期的记录重写到新的段文件中，回收被覆盖和删除的记录占用的空间
+func (d *DiskTier[K, V]) Compact() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.compact()
+}
+
+// Close 关闭段文件
+func (d *DiskTier[K, V]) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.file.Close()
+}
+
+// scan 从头扫描段文件重建索引
+func (d *DiskTier[K, V]) scan() error {
+	size, err := scanRecords(d.file, func(payload []byte, offset, length int64) error {
+		var rec diskRecord[K, V]
+		if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec); err != nil {
+			return err
+		}
+
+		if e, ok := d.index[rec.Key]; ok {
+			d.drop(e)
+		}
+		if !rec.Deleted {
+			d.index[rec.Key] = d.order.PushFront(diskEntry[K]{rec.Key, offset, length, rec.ExpireAt})
+			d.live += length
+		}
+		return nil
+	})
+	d.size = size
+	return err
+}
+
+// append 将记录追加到段文件末尾
+// 返回值: 记录的偏移、总长度和错误
+// 调用前必须持有锁
+func (d *DiskTier[K, V]) append(rec diskRecord[K, V]) (int64, int64, error) {
+	buf, err := encodeRecord(rec)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset := d.size
+	if _, err := d.file.WriteAt(buf, offset); err != nil {
+		return 0, 0, err
+	}
+	d.size += int64(len(buf))
+	return offset, int64(len(buf)), nil
+}
+
+// read 读取并解码指定位置的记录
+// 调用前必须持有锁
+func (d *DiskTier[K, V]) read(offset, length int64) (diskRecord[K, V], error) {
+	var rec diskRecord[K, V]
+
+	buf := make([]byte, length)
+	if _, err := d.file.ReadAt(buf, offset); err != nil {
+		return rec, err
+	}
+	payload, err := readRecord(bytes.NewReader(buf), length-diskHeaderSize)
+	if err != nil {
+		return rec, err
+	}
+	err = gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec)
+	return rec, err
+}
+
+// drop 从索引中删除一项
+// 调用前必须持有锁
+func (d *DiskTier[K, V]) drop(e *list.Element) {
+	item := d.order.Remove(e).(diskEntry[K])
+	delete(d.index, item.key)
+	d.live -= item.length
+}
+
+// enforceBudget 丢弃最早写入的项，直到有效记录不超过字节预算
+// 被丢弃的记录会在下次压缩时回收；重新打开时按相同顺序重新应用预算
+// 调用前必须持有锁
+func (d *DiskTier[K, V]) enforceBudget() {
+	for d.maxBytes > 0 && d.live > d.maxBytes {
+		e := d.order.Back()
+		d.orphans[e.Value.(diskEntry[K]).key] = struct{}{}
+		d.drop(e)
+	}
+}
+
+// maybeCompact 在垃圾数据超过有效数据时压缩段文件
+// 调用前必须持有锁
+func (d *DiskTier[K, V]) maybeCompact() error {
+	garbage := d.size - d.live
+	if garbage < diskCompactMinGarbage || garbage <= d.live {
+		return nil
+	}
+	return d.compact()
+}
+
+// compact 按写入顺序将有效记录重写到临时文件，然后原子替换段文件
+// 调用前必须持有锁
+func (d *DiskTier[K, V]) compact() error {
+	path := filepath.Join(d.dir, diskSegmentName)
+	tmp, err := os.OpenFile(path+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var offset int64
+	offsets := make(map[K]int64, d.order.Len())
+	for e := d.order.Back(); e != nil; e = e.Prev() {
+		item := e.Value.(diskEntry[K])
+		if !item.expireAt.IsZero() && !now.Before(item.expireAt) {
+			continue
+		}
+
+		buf := make([]byte, item.length)
+		if _, err := d.file.ReadAt(buf, item.offset); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return fmt.Errorf("lru: compact read: %w", err)
+		}
+		if _, err := tmp.WriteAt(buf, offset); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		offsets[item.key] = offset
+		offset += item.length
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	d.file.Close()
+	d.file = tmp
+	d.size = offset
+	d.live = 0
+	// 新的段文件只包含索引中的记录
+	d.orphans = make(map[K]struct{})
+	for e := d.order.Front(); e != nil; {
+		next := e.Next()
+		item := e.Value.(diskEntry[K])
+		if off, ok := offsets[item.key]; ok {
+			item.offset = off
+			e.Value = item
+			d.live += item.length
+		} else {
+			d.order.Remove(e)
+			delete(d.index, item.key)
+		}
+		e = next
+	}
+	return nil
+}
+
+// encodeRecord 使用gob编码记录，并加上长度和CRC32校验和头部
+func encodeRecord(rec any) ([]byte, error) {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(rec); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, diskHeaderSize+payload.Len())
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(payload.Len()))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload.Bytes()))
+	copy(buf[diskHeaderSize:], payload.Bytes())
+	return buf, nil
+}
+
+// scanRecords 从头读取文件中的所有记录，对每条记录的负载调用fn
+// 遇到不完整、校验失败或fn返回错误的记录时，将文件截断到该记录之前
+// 返回值: 最后一条完整记录之后的偏移和错误
+func scanRecords(file *os.File, fn func(payload []byte, offset, length int64) error) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r := bufio.NewReader(file)
+
+	var offset int64
+	for {
+		payload, err := readRecord(r, info.Size()-offset-diskHeaderSize)
+		if errors.Is(err, io.EOF) {
+			return offset, nil
+		}
+		length := int64(diskHeaderSize + len(payload))
+		if err == nil {
+			err = fn(payload, offset, length)
+		}
+		if err != nil {
+			// 末尾记录不完整或已损坏，丢弃之后的数据
+			return offset, file.Truncate(offset)
+		}
+		offset += length
+	}
+}
+
+// readRecord 读取一条记录的负载并校验
+// 参数 limit: 负载长度的上限，用于在头部损坏时避免分配过大的内存
+// 没有更多记录时返回io.EOF，记录不完整或已损坏时返回其他错误
+func readRecord(r io.Reader, limit int64) ([]byte, error) {
+	var header [diskHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	n := binary.LittleEndian.Uint32(header[0:4])
+	if int64(n) > limit {
+		return nil, io.ErrUnexpectedEOF
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(header[4:8]) {
+		return nil, errors.New("lru: record checksum mismatch")
+	}
+	return payload, nil
+}
@@ -0,0 +1,231 @@
+package lru
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 测试磁盘缓存的读写、过期和重新打开
+func TestDiskTier(t *testing.T) {
+	t.Log("🔍 测试: 磁盘缓存读写和持久化")
+	dir := t.TempDir()
+
+	disk, err := OpenDiskTier[string, string](dir, 0)
+	if err != nil {
+		t.Fatalf("❌ 打开磁盘缓存失败: %v", err)
+	}
+
+	expireAt := time.Now().Add(time.Hour).Round(0)
+	disk.Put("a", "apple", expireAt)
+	disk.Put("b", "banana", time.Time{})
+	disk.Put("c", "cherry", time.Now().Add(20*time.Millisecond))
+	disk.Put("b", "blueberry", time.Time{})
+	disk.Remove("a")
+
+	if _, _, ok := disk.Fetch("a"); ok {
+		t.Error("❌ 'a'应已被删除")
+	}
+	if v, exp, ok := disk.Fetch("b"); !ok || v != "blueberry" || !exp.IsZero() {
+		t.Errorf("❌ 'b'应为覆盖后的值: %v, %v, %v", v, exp, ok)
+	} else {
+		t.Log("✅ 覆盖写入后读取到最新值")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, _, ok := disk.Fetch("c"); ok {
+		t.Error("❌ 'c'应已过期")
+	} else {
+		t.Log("✅ 磁盘缓存保留并检查过期时间")
+	}
+
+	disk.Put("d", "date", expireAt)
+	if err := disk.Close(); err != nil {
+		t.Fatalf("❌ 关闭磁盘缓存失败: %v", err)
+	}
+
+	disk, err = OpenDiskTier[string, string](dir, 0)
+	if err != nil {
+		t.Fatalf("❌ 重新打开磁盘缓存失败: %v", err)
+	}
+	defer disk.Close()
+
+	if _, _, ok := disk.Fetch("a"); ok {
+		t.Error("❌ 重新打开后不应恢复已删除的'a'")
+	}
+	if v, _, ok := disk.Fetch("b"); !ok || v != "blueberry" {
+		t.Errorf("❌ 重新打开后'b'丢失: %v, %v", v, ok)
+	}
+	if v, exp, ok := disk.Fetch("d"); !ok || v != "date" || !exp.Equal(expireAt) {
+		t.Errorf("❌ 重新打开后'd'或其过期时间丢失: %v, %v, %v", v, exp, ok)
+	} else {
+		t.Log("✅ 重新打开后数据和过期时间保持不变")
+	}
+}
+
+// 测试字节预算和压缩
+func TestDiskTierBudgetAndCompact(t *testing.T) {
+	t.Log("🔍 测试: 磁盘缓存字节预算和压缩")
+	dir := t.TempDir()
+
+	disk, err := OpenDiskTier[int, int](dir, 0)
+	if err != nil {
+		t.Fatalf("❌ 打开磁盘缓存失败: %v", err)
+	}
+	disk.Put(9, 9, time.Time{})
+	recordSize := disk.Bytes()
+	disk.Close()
+
+	// 预算只能容纳3条记录
+	os.RemoveAll(dir)
+	disk, err = OpenDiskTier[int, int](dir, 3*recordSize)
+	if err != nil {
+		t.Fatalf("❌ 打开磁盘缓存失败: %v", err)
+	}
+	defer disk.Close()
+
+	for i := 1; i <= 5; i++ {
+		disk.Put(i, i, time.Time{})
+	}
+	if disk.Len() != 3 {
+		t.Errorf("❌ 超出预算后应只保留3项, 实际%d项", disk.Len())
+	}
+	if _, _, ok := disk.Fetch(1); ok {
+		t.Error("❌ 最早写入的项应被丢弃")
+	} else {
+		t.Log("✅ 超出预算时丢弃最早写入的项")
+	}
+
+	before := disk.Bytes()
+	if err := disk.Compact(); err != nil {
+		t.Fatalf("❌ 压缩失败: %v", err)
+	}
+	if after := disk.Bytes(); after >= before {
+		t.Errorf("❌ 压缩后段文件应变小: %d -> %d", before, after)
+	} else {
+		t.Logf("✅ 压缩后段文件从%d字节减少到%d字节", before, after)
+	}
+	for i := 3; i <= 5; i++ {
+		if v, _, ok := disk.Fetch(i); !ok || v != i {
+			t.Errorf("❌ 压缩后'%d'丢失: %v, %v", i, v, ok)
+		}
+	}
+}
+
+// 测试删除因超出预算被丢弃的键后重新打开不会恢复该键
+func TestDiskTierRemoveDropped(t *testing.T) {
+	t.Log("🔍 测试: 删除已被预算丢弃的键")
+	dir := t.TempDir()
+
+	disk, err := OpenDiskTier[string, string](dir, 0)
+	if err != nil {
+		t.Fatalf("❌ 打开磁盘缓存失败: %v", err)
+	}
+	disk.Put("a", "old", time.Time{})
+	recordSize := disk.Bytes()
+	disk.Close()
+
+	// 预算只能容纳1条记录，重新打开时丢弃"a"
+	disk, err = OpenDiskTier[string, string](dir, recordSize)
+	if err != nil {
+		t.Fatalf("❌ 打开磁盘缓存失败: %v", err)
+	}
+	disk.Put("b", "new", time.Time{})
+	if ok, err := disk.Remove("a"); ok || err != nil {
+		t.Errorf("❌ 已被丢弃的'a'应返回false: %v, %v", ok, err)
+	}
+	disk.Remove("b")
+	disk.Close()
+
+	disk, err = OpenDiskTier[string, string](dir, recordSize)
+	if err != nil {
+		t.Fatalf("❌ 重新打开磁盘缓存失败: %v", err)
+	}
+	defer disk.Close()
+	if v, _, ok := disk.Fetch("a"); ok {
+		t.Errorf("❌ 重新打开后不应恢复已删除的'a', 实际 %v", v)
+	} else {
+		t.Log("✅ 删除已丢弃的键时也写入了删除记录")
+	}
+}
+
+// 测试损坏的段文件尾部被截断
+func TestDiskTierTruncatedTail(t *testing.T) {
+	t.Log("🔍 测试: 段文件尾部损坏时的恢复")
+	dir := t.TempDir()
+
+	disk, _ := OpenDiskTier[string, int](dir, 0)
+	disk.Put("a", 1, time.Time{})
+	disk.Put("b", 2, time.Time{})
+	size := disk.Bytes()
+	disk.Close()
+
+	// 截掉最后一条记录的一部分
+	os.Truncate(filepath.Join(dir, diskSegmentName), size-3)
+
+	disk, err := OpenDiskTier[string, int](dir, 0)
+	if err != nil {
+		t.Fatalf("❌ 重新打开磁盘缓存失败: %v", err)
+	}
+	defer disk.Close()
+
+	if v, _, ok := disk.Fetch("a"); !ok || v != 1 {
+		t.Errorf("❌ 完整的记录应保留: %v, %v", v, ok)
+	}
+	if _, _, ok := disk.Fetch("b"); ok {
+		t.Error("❌ 不完整的记录应被丢弃")
+	} else {
+		t.Log("✅ 不完整的尾部记录被丢弃")
+	}
+}
+
+// 测试磁盘缓存作为两级缓存的L2
+func TestDiskTierAsL2(t *testing.T) {
+	t.Log("🔍 测试: 磁盘缓存接收L1淘汰的项")
+	disk, err := OpenDiskTier[string, int](t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("❌ 打开磁盘缓存失败: %v", err)
+	}
+	defer disk.Close()
+
+	cache := NewTiered(New[string, int](1), Tier[string, int](disk)).Demote(true)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Errorf("❌ 应从磁盘缓存中取回'a': %v, %v", v, ok)
+	} else {
+		t.Log("✅ L1淘汰的项可从磁盘缓存取回")
+	}
+}
+
+// 测试删除从未写入的键不写入磁盘
+func TestDiskTierRemoveAbsent(t *testing.T) {
+	t.Log("🔍 测试: 删除不存在的键不追加删除记录")
+	disk, err := OpenDiskTier[string, string](t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("❌ 打开磁盘缓存失败: %v", err)
+	}
+	defer disk.Close()
+
+	disk.Put("a", "1", time.Time{})
+	size := disk.Bytes()
+	for i := 0; i < 100; i++ {
+		if ok, err := disk.Remove("b"); ok || err != nil {
+			t.Fatalf("❌ 删除不存在的'b'应返回false: %v, %v", ok, err)
+		}
+	}
+	if disk.Bytes() != size {
+		t.Errorf("❌ 删除不存在的键不应写入段文件: %d -> %d", size, disk.Bytes())
+	} else {
+		t.Log("✅ 段文件大小不变")
+	}
+
+	disk.Remove("a")
+	size = disk.Bytes()
+	disk.Remove("a")
+	if disk.Bytes() != size {
+		t.Errorf("❌ 重复删除不应再次写入删除记录: %d -> %d", size, disk.Bytes())
+	}
+}
@@ -0,0 +1,119 @@
+package lru
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EntryInfo 是单个缓存项的值及其元数据
+// 关闭元数据记录时写入的项，CreatedAt、LastAccess、LastUpdate为零值，AccessCount为0
+type EntryInfo[V any] struct {
+	Value       V             // 缓存项的值
+	CreatedAt   time.Time     // 首次写入的时间，更新值不会改变
+	LastAccess  time.Time     // 最近一次命中的时间，从未命中时为零值
+	LastUpdate  time.Time     // 最近一次写入的时间
+	ExpireAt    time.Time     // 过期时间点，零值表示永不过期
+	TTL         time.Duration // 剩余生存时间，永不过期时为0
+	AccessCount uint64        // 命中次数，Get、Lookup和GetEntry会增加，Peek和PeekEntry不会
+	Cost        int64         // 成本，未设置时为0
+}
+
+// entryMeta 是缓存项的时间和访问元数据
+// 写入时间只在写锁下修改，访问时间和次数在读锁下命中时也会修改，因此使用原子操作
+type entryMeta struct {
+	created  int64         // 首次写入时间，Unix纳秒
+	updated  int64         // 最近写入时间，Unix纳秒
+	accessed atomic.Int64  // 最近命中时间，Unix纳秒，为0时从未命中
+	accesses atomic.Uint64 // 命中次数
+}
+
+// trackedNode 将节点和元数据放在一起，插入时只需要一次分配
+type trackedNode[K comparable, V any] struct {
+	node node[K, V]
+	meta entryMeta
+}
+
+// TrackEntries 设置是否记录缓存项的创建时间、访问时间、更新时间和命中次数
+// 参数 enable: 默认为true；内存敏感的缓存可以设为false，每项节省约32字节，
+// 同时命中时不再需要原子写入
+// 返回缓存实例本身，支持链式调用
+// 注意: 只影响之后插入的项，已有的项保持原来的设置直到被删除
+func (c *Cache[K, V]) TrackEntries(enable bool) *Cache[K, V] {
+	c.mu.Lock()
+	c.untracked = !enable
+	c.mu.Unlock()
+	return c
+}
+
+// GetEntry 获取缓存项的值和元数据，与Get一样会更新位置、命中次数和命中统计
+// 参数 key: 要获取的缓存项键
+// 返回值: 缓存项的信息和是否存在/有效的标志，负缓存项和错误项视为不存在
+// 注意: 只查找缓存本身，不会调用Store或加载函数
+func (c *Cache[K, V]) GetEntry(key K) (EntryInfo[V], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, result := c.lookup(key, true)
+	c.record(result)
+	if result != LookupHit {
+		return EntryInfo[V]{}, false
+	}
+	return c.items[key].info(c.now()), true
+}
+
+// PeekEntry 获取缓存项的值和元数据，不更新位置、命中次数和命中统计
+// 参数 key: 要获取的缓存项键
+// 返回值: 缓存项的信息和是否存在/有效的标志，负缓存项和错误项视为不存在
+func (c *Cache[K, V]) PeekEntry(key K) (EntryInfo[V], bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, ok := c.peek(key); !ok {
+		return EntryInfo[V]{}, false
+	}
+	return c.items[key].info(c.now()), true
+}
+
+// newNode 为新缓存项分配节点，记录元数据时节点和元数据一次分配
+// 调用前必须持有锁
+func (c *Cache[K, V]) newNode(item entry[K, V]) *node[K, V] {
+	if c.untracked {
+		return &node[K, V]{entry: item}
+	}
+	t := &trackedNode[K, V]{node: node[K, V]{entry: item}}
+	t.node.meta = &t.meta
+	t.meta.created = c.now().UnixNano()
+	t.meta.updated = t.meta.created
+	return &t.node
+}
+
+// access 记录一次命中，可以在读锁下调用
+func (n *node[K, V]) access(now time.Time) {
+	if m := n.meta; m != nil {
+		m.accessed.Store(now.UnixNano())
+		m.accesses.Add(1)
+	}
+}
+
+// info 返回节点中缓存项的信息
+// 调用前必须持有读锁或写锁
+func (n *node[K, V]) info(now time.Time) EntryInfo[V] {
+	item := &n.entry
+	info := EntryInfo[V]{
+		Value:    item.value,
+		ExpireAt: item.expireAt,
+		Cost:     item.cost,
+	}
+	if !item.expireAt.IsZero() {
+		info.TTL = item.expireAt.Sub(now)
+	}
+	if m := n.meta; m != nil {
+		info.CreatedAt = time.Unix(0, m.created)
+		info.LastUpdate = time.Unix(0, m.updated)
+		if ns := m.accessed.Load(); ns != 0 {
+			info.LastAccess = time.Unix(0, ns)
+		}
+		info.AccessCount = m.accesses.Load()
+	}
+	return info
+}
@@ -0,0 +1,104 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+// 测试获取缓存项的元数据
+func TestGetEntry(t *testing.T) {
+	t.Log("🔍 测试: GetEntry和PeekEntry返回的元数据")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cache := New[string, int](5).TTL(time.Hour)
+	cache.now = clock.Now
+
+	created := clock.Now()
+	cache.SetWithOptions("a", 1, EntryOptions{Cost: 3})
+
+	info, ok := cache.PeekEntry("a")
+	if !ok || info.Value != 1 || !info.CreatedAt.Equal(created) || !info.LastUpdate.Equal(created) ||
+		!info.LastAccess.IsZero() || info.AccessCount != 0 || info.Cost != 3 || info.TTL != time.Hour {
+		t.Errorf("❌ 新写入项的信息不正确: %+v", info)
+	} else {
+		t.Log("✅ 新写入项: 创建时间、更新时间、成本和剩余TTL正确，尚未被访问")
+	}
+
+	clock.Advance(time.Minute)
+	cache.Get("a")
+	clock.Advance(time.Minute)
+	accessed := clock.Now()
+	cache.Get("a")
+	cache.Peek("a")
+	clock.Advance(time.Minute)
+	updated := clock.Now()
+	cache.Set("a", 2)
+
+	clock.Advance(time.Minute)
+	info, ok = cache.PeekEntry("a")
+	if !ok || info.Value != 2 || info.AccessCount != 2 || !info.LastAccess.Equal(accessed) ||
+		!info.CreatedAt.Equal(created) || !info.LastUpdate.Equal(updated) {
+		t.Errorf("❌ 访问和更新后的信息不正确: %+v", info)
+	} else {
+		t.Log("✅ Get计入访问次数，Peek不计入；更新保留创建时间")
+	}
+	if info.TTL != 59*time.Minute || !info.ExpireAt.Equal(updated.Add(time.Hour)) {
+		t.Errorf("❌ 剩余TTL应为59m, 实际%v, 过期时间%v", info.TTL, info.ExpireAt)
+	}
+
+	hits := cache.Stats().Hits
+	if info, ok = cache.GetEntry("a"); !ok || info.AccessCount != 3 || !info.LastAccess.Equal(clock.Now()) {
+		t.Errorf("❌ GetEntry应计入一次访问: %+v", info)
+	}
+	if cache.Stats().Hits != hits+1 {
+		t.Error("❌ GetEntry应更新命中统计")
+	}
+
+	cache.Set("b", 1).Expire(0)
+	if info, ok = cache.PeekEntry("b"); !ok || info.TTL != 0 || !info.ExpireAt.IsZero() {
+		t.Errorf("❌ 永不过期项的剩余TTL应为0: %+v", info)
+	}
+	cache.SetMissing("c", 0)
+	clock.Advance(2 * time.Hour)
+	for _, key := range []string{"a", "c", "d"} {
+		if _, ok := cache.GetEntry(key); ok {
+			t.Errorf("❌ %s: 过期、负缓存或不存在的项不应返回信息", key)
+		}
+	}
+}
+
+// 测试关闭元数据记录
+func TestTrackEntries(t *testing.T) {
+	t.Log("🔍 测试: 关闭元数据记录后只返回值、过期时间和成本")
+	cache := New[string, int](5).TrackEntries(false)
+	cache.SetWithOptions("a", 1, EntryOptions{Cost: 2})
+	cache.Get("a")
+
+	info, ok := cache.GetEntry("a")
+	if !ok || info.Value != 1 || info.Cost != 2 || !info.CreatedAt.IsZero() ||
+		!info.LastUpdate.IsZero() || !info.LastAccess.IsZero() || info.AccessCount != 0 {
+		t.Errorf("❌ 关闭记录后的信息不正确: %+v", info)
+	} else {
+		t.Log("✅ 没有记录时间和访问次数")
+	}
+
+	cache.TrackEntries(true).Set("b", 1)
+	cache.Get("b")
+	if info, _ := cache.PeekEntry("b"); info.AccessCount != 1 || info.CreatedAt.IsZero() {
+		t.Errorf("❌ 重新开启后插入的项应记录元数据: %+v", info)
+	}
+
+	opts, err := NewWithOptions[string, int](WithEntryTracking(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Set("a", 1)
+	if info, _ := opts.PeekEntry("a"); !info.CreatedAt.IsZero() {
+		t.Error("❌ WithEntryTracking(false)应关闭元数据记录")
+	}
+
+	n := testing.AllocsPerRun(100, func() {
+		cache.Delete("c")
+		cache.Set("c", 1)
+	})
+	t.Logf("📊 开启记录时插入一项分配%.0f次", n)
+}
@@ -0,0 +1,212 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// evictionWindow 是淘汰时比较优先级的最久未使用项数量
+const evictionWindow = 8
+
+// EntryOptions 是SetWithOptions写入单个缓存项时使用的元数据
+// TTL、ExpireAt和NoExpire最多设置一个，都未设置时与Set相同使用默认过期时间
+type EntryOptions struct {
+	TTL      time.Duration // 过期时间，不能为负数
+	ExpireAt time.Time     // 过期时间点
+	NoExpire bool          // 永不过期，不使用默认过期时间
+	Cost     int64         // 成本，计入MaxCost设置的成本上限，为0时按1计算，不能为负数；负缓存项和错误项不计成本
+	Priority int           // 优先级，淘汰时在最久未使用的若干项中优先淘汰优先级最低的项
+	Tags     []string      // 标签，可通过InvalidateTag删除带有某个标签的所有项
+	Pinned   bool          // 置顶，置顶的项不会因容量或成本上限被淘汰，为false时保留该键原有的置顶状态
+}
+
+// validate 检查选项是否有效
+func (o EntryOptions) validate() error {
+	if o.TTL < 0 {
+		return fmt.Errorf("lru: ttl must not be negative, got %v", o.TTL)
+	}
+	if o.Cost < 0 {
+		return fmt.Errorf("lru: cost must not be negative, got %d", o.Cost)
+	}
+	n := 0
+	for _, set := range []bool{o.TTL > 0, !o.ExpireAt.IsZero(), o.NoExpire} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
+		return errors.New("lru: at most one of TTL, ExpireAt and NoExpire may be set")
+	}
+	return nil
+}
+
+// SetWithOptions 添加或更新缓存项，并在同一次加锁中应用过期时间、成本、优先级和标签
+// 参数 key: 缓存项的键
+// 参数 value: 缓存项的值
+// 参数 opts: 缓存项的元数据，会替换该键原有的元数据
+// 返回值: 选项无效、成本超过上限、缓存已关闭、置顶项占满缓存或达到置顶上限（ErrFull）时的错误，
+// 以及同步写入Store的错误，出错时缓存保持不变
+func (c *Cache[K, V]) SetWithOptions(key K, value V, opts EntryOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	kl, err := c.lockWrite(key, value, opts.Cost, opts.Pinned)
+	if err != nil {
+		return err
+	}
+	if kl != nil {
+		defer kl.Unlock()
+	}
+	defer c.mu.Unlock()
+
+	var expireAt time.Time
+	switch {
+	case opts.TTL > 0:
+		expireAt = c.now().Add(opts.TTL)
+	case !opts.ExpireAt.IsZero():
+		expireAt = opts.ExpireAt
+	case !opts.NoExpire:
+		expireAt = c.defaultExpireAt(key)
+	}
+
+	c.setEntry(entry[K, V]{
+		key:      key,
+		value:    value,
+		expireAt: expireAt,
+		cost:     opts.Cost,
+		priority: opts.Priority,
+		tags:     slices.Compact(slices.Sorted(slices.Values(opts.Tags))),
+		pinned:   opts.Pinned,
+	})
+	if c.writeBehind {
+		c.markDirty(key)
+	}
+	return nil
+}
+
+// MaxCost 设置所有缓存项的成本上限
+// 参数 max: 成本上限，小于等于0时不限制
+// 返回缓存实例本身，支持链式调用
+// 总成本超过上限时按与容量淘汰相同的规则淘汰缓存项，除非其余项都已置顶，否则不会淘汰最近写入的项
+func (c *Cache[K, V]) MaxCost(max int64) *Cache[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if max < 0 {
+		max = 0
+	}
+	c.maxCost = max
+	c.evict()
+	return c
+}
+
+// Cost 返回当前所有缓存项的成本之和
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cost
+}
+
+// InvalidateTag 删除带有指定标签的所有缓存项
+// 参数 tag: 标签
+// 返回值: 删除的项数
+// 只删除缓存中的项，不会从绑定的Store删除；延迟写入模式下脏项会先写入Store
+func (c *Cache[K, V]) InvalidateTag(tag string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for key := range c.tags[tag] {
+		c.removeElement(c.items[key])
+		c.logOp(walRecord[K, V]{Op: walDelete, Key: key})
+		count++
+	}
+	return count
+}
+
+// weight 返回该项计入总成本的值
+// 负缓存项和不带旧值的错误项不携带值，不计入成本
+func (e *entry[K, V]) weight() int64 {
+	if !e.hasValue() {
+		return 0
+	}
+	if e.cost > 0 {
+		return e.cost
+	}
+	return 1
+}
+
+// track 将缓存项计入总成本、置顶项数量和标签索引
+// 调用前必须持有锁
+func (c *Cache[K, V]) track(item *entry[K, V]) {
+	c.cost += item.weight()
+	if item.priority != 0 {
+		c.prioritized++
+	}
+	if item.pinned {
+		c.pinned++
+	}
+	for _, tag := range item.tags {
+		if c.tags == nil {
+			c.tags = make(map[string]map[K]struct{})
+		}
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[K]struct{})
+			c.tags[tag] = keys
+		}
+		keys[item.key] = struct{}{}
+	}
+}
+
+// untrack 将缓存项从总成本、置顶项数量和标签索引中移除
+// 调用前必须持有锁
+func (c *Cache[K, V]) untrack(item *entry[K, V]) {
+	c.cost -= item.weight()
+	if item.priority != 0 {
+		c.prioritized--
+	}
+	if item.pinned {
+		c.pinned--
+	}
+	for _, tag := range item.tags {
+		if keys, ok := c.tags[tag]; ok {
+			delete(keys, item.key)
+			if len(keys) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+}
+
+// victim 选择要淘汰的项
+// 跳过置顶项，在最久未使用的evictionWindow个项中选择优先级最低的项，优先级相同时选择最久未使用的，
+// 链表头部的项只在没有其他可淘汰的项时才会被选中
+// 返回值: 要淘汰的节点，所有项都已置顶时返回nil
+// 调用前必须持有锁
+func (c *Cache[K, V]) victim() *node[K, V] {
+	back := c.list.Back()
+	if (c.prioritized == 0 && c.pinned == 0) || back == nil {
+		return back
+	}
+
+	front := c.list.Front()
+	var victim *node[K, V]
+	n := 0
+	for e := back; e != nil && n < evictionWindow; e = e.Prev() {
+		if e.entry.pinned {
+			continue
+		}
+		if e == front && victim != nil {
+			break
+		}
+		if victim == nil || e.entry.priority < victim.entry.priority {
+			victim = e
+		}
+		n++
+	}
+	return victim
+}
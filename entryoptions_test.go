@@ -0,0 +1,162 @@
+package lru
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// 测试一次写入过期时间等元数据
+func TestSetWithOptions(t *testing.T) {
+	t.Log("🔍 测试: SetWithOptions设置过期时间")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cache := New[string, int](5).TTL(time.Hour)
+	cache.now = clock.Now
+
+	cache.SetWithOptions("ttl", 1, EntryOptions{TTL: time.Minute})
+	cache.SetWithOptions("at", 2, EntryOptions{ExpireAt: clock.Now().Add(time.Minute)})
+	cache.SetWithOptions("forever", 3, EntryOptions{NoExpire: true})
+	cache.SetWithOptions("default", 4, EntryOptions{})
+
+	clock.Advance(2 * time.Minute)
+	if keys := cache.Keys(); !slices.Equal(keys, []string{"default", "forever"}) {
+		t.Errorf("❌ 剩余的键应为[default forever], 实际%v", keys)
+	} else {
+		t.Log("✅ TTL、ExpireAt、NoExpire和默认过期时间均生效")
+	}
+
+	invalid := map[string]EntryOptions{
+		"负TTL":             {TTL: -time.Second},
+		"负成本":              {Cost: -1},
+		"TTL和NoExpire同时设置": {TTL: time.Second, NoExpire: true},
+		"TTL和ExpireAt同时设置": {TTL: time.Second, ExpireAt: clock.Now()},
+	}
+	for name, opts := range invalid {
+		if err := cache.SetWithOptions("x", 0, opts); err == nil {
+			t.Errorf("❌ %s: 应返回错误", name)
+		} else {
+			t.Logf("✅ %s: %v", name, err)
+		}
+	}
+	if _, ok := cache.Peek("x"); ok {
+		t.Error("❌ 选项无效时不应写入缓存")
+	}
+}
+
+// 测试按成本淘汰
+func TestMaxCost(t *testing.T) {
+	t.Log("🔍 测试: 总成本超过上限时淘汰")
+	cache := New[string, int](10).MaxCost(10)
+
+	cache.SetWithOptions("a", 1, EntryOptions{Cost: 4})
+	cache.SetWithOptions("b", 2, EntryOptions{Cost: 4})
+	cache.Set("c", 3) // 成本按1计算
+	if cache.Cost() != 9 {
+		t.Errorf("❌ 总成本应为9, 实际%d", cache.Cost())
+	}
+
+	cache.SetWithOptions("d", 4, EntryOptions{Cost: 5})
+	if keys := cache.Keys(); !slices.Equal(keys, []string{"d", "c", "b"}) || cache.Cost() != 10 {
+		t.Errorf("❌ 应淘汰最久未使用的'a', 实际键%v, 成本%d", keys, cache.Cost())
+	} else {
+		t.Log("✅ 超出成本上限时淘汰最久未使用的项")
+	}
+
+	if err := cache.SetWithOptions("e", 5, EntryOptions{Cost: 11}); err == nil {
+		t.Error("❌ 成本超过上限的项应被拒绝")
+	}
+	if err := cache.Validate(); err != nil {
+		t.Errorf("❌ 内部状态不一致: %v", err)
+	}
+}
+
+// 测试负缓存项和错误项不计成本
+func TestMaxCostValueless(t *testing.T) {
+	t.Log("🔍 测试: 不携带值的项不计入总成本")
+	cache := New[int, int](10).MaxCost(3).ErrorTTL(time.Minute, 0)
+	cache.SetWithOptions(1, 1, EntryOptions{Cost: 3})
+	cache.SetMissing(2, time.Minute)
+	cache.GetOrLoad(3, func(int) (int, error) { return 0, errors.New("boom") })
+
+	if _, ok := cache.Peek(1); !ok || cache.Cost() != 3 {
+		t.Errorf("❌ 负缓存项和错误项不应挤出有效值, 总成本%d", cache.Cost())
+	} else {
+		t.Log("✅ 有效值保留，总成本仍为3")
+	}
+	if err := cache.Validate(); err != nil {
+		t.Errorf("❌ Validate() = %v", err)
+	}
+
+	cache.Set(2, 2)
+	if _, ok := cache.Peek(1); ok || cache.Cost() != 1 {
+		t.Errorf("❌ 负缓存项变为有效值后应计入成本, 总成本%d", cache.Cost())
+	}
+}
+
+// 测试优先级影响淘汰顺序
+func TestPriorityEviction(t *testing.T) {
+	t.Log("🔍 测试: 淘汰时优先淘汰优先级低的项")
+	var evicted []string
+	cache := New[string, int](3).OnEvict(func(key string, value int, expireAt time.Time) {
+		evicted = append(evicted, key)
+	})
+
+	cache.SetWithOptions("important", 1, EntryOptions{Priority: 10})
+	cache.Set("a", 2)
+	cache.Set("b", 3)
+	cache.Set("c", 4)
+	cache.Set("d", 5)
+
+	if !slices.Equal(evicted, []string{"a", "b"}) {
+		t.Errorf("❌ 应依次淘汰'a'和'b', 实际%v", evicted)
+	} else {
+		t.Log("✅ 高优先级的最久未使用项被保留")
+	}
+	if _, ok := cache.Peek("important"); !ok {
+		t.Error("❌ 高优先级项不应被淘汰")
+	}
+	if err := cache.Validate(); err != nil {
+		t.Errorf("❌ 内部状态不一致: %v", err)
+	}
+}
+
+// 测试按标签删除
+func TestInvalidateTag(t *testing.T) {
+	t.Log("🔍 测试: InvalidateTag删除带有标签的项")
+	path := filepath.Join(t.TempDir(), "cache.wal")
+	cache := New[string, int](10)
+	if err := cache.OpenWAL(path, SyncNever); err != nil {
+		t.Fatalf("❌ 打开预写日志失败: %v", err)
+	}
+
+	cache.SetWithOptions("u1", 1, EntryOptions{Tags: []string{"user", "user"}})
+	cache.SetWithOptions("u2", 2, EntryOptions{Tags: []string{"user", "vip"}})
+	cache.SetWithOptions("p1", 3, EntryOptions{Tags: []string{"product"}})
+	cache.Set("u1", 4) // 重新写入后不再带有标签
+
+	if n := cache.InvalidateTag("user"); n != 1 {
+		t.Errorf("❌ 应删除1项, 实际%d项", n)
+	}
+	if keys := cache.Keys(); !slices.Equal(keys, []string{"u1", "p1"}) {
+		t.Errorf("❌ 剩余的键应为[u1 p1], 实际%v", keys)
+	} else {
+		t.Log("✅ 只删除了当前带有该标签的项")
+	}
+	if err := cache.Validate(); err != nil {
+		t.Errorf("❌ 内部状态不一致: %v", err)
+	}
+	cache.Close()
+
+	recovered := New[string, int](10)
+	if err := recovered.OpenWAL(path, SyncNever); err != nil {
+		t.Fatalf("❌ 恢复失败: %v", err)
+	}
+	defer recovered.Close()
+	if n := recovered.InvalidateTag("product"); n != 1 || recovered.Size() != 1 {
+		t.Errorf("❌ 恢复后标签应保留, 删除%d项, 剩余%d项", n, recovered.Size())
+	} else {
+		t.Log("✅ 标签通过预写日志恢复")
+	}
+}
@@ -0,0 +1,224 @@
+package lru
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock 是可手动推进的假时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func (f *fakeClock) Advance(d time.Duration) { f.now = f.now.Add(d) }
+
+// modelEntry 是参考模型中的条目
+type modelEntry struct {
+	key      uint8
+	value    int
+	expireAt time.Time
+}
+
+// model 是LRU缓存的简单参考实现，entries[0]为最近使用的项
+type model struct {
+	entries []modelEntry
+	size    int
+	ttl     time.Duration
+	clock   *fakeClock
+}
+
+func (m *model) find(key uint8) int {
+	return slices.IndexFunc(m.entries, func(e modelEntry) bool { return e.key == key })
+}
+
+// expired 与Get的判断一致：到达过期时间点即视为过期
+func (m *model) expired(e modelEntry) bool {
+	return !e.expireAt.IsZero() && !m.clock.Now().Before(e.expireAt)
+}
+
+func (m *model) toFront(i int) {
+	e := m.entries[i]
+	m.entries = slices.Delete(m.entries, i, i+1)
+	m.entries = slices.Insert(m.entries, 0, e)
+}
+
+func (m *model) set(key uint8, value int) {
+	var expireAt time.Time
+	if m.ttl > 0 {
+		expireAt = m.clock.Now().Add(m.ttl)
+	}
+	if i := m.find(key); i >= 0 {
+		if m.entries[i].expireAt.IsZero() {
+			expireAt = time.Time{}
+		}
+		m.entries[i] = modelEntry{key, value, expireAt}
+		m.toFront(i)
+		return
+	}
+	m.entries = slices.Insert(m.entries, 0, modelEntry{key, value, expireAt})
+	m.evict()
+}
+
+func (m *model) get(key uint8) (int, bool) {
+	i := m.find(key)
+	if i < 0 {
+		return 0, false
+	}
+	if m.expired(m.entries[i]) {
+		m.entries = slices.Delete(m.entries, i, i+1)
+		return 0, false
+	}
+	m.toFront(i)
+	return m.entries[0].value, true
+}
+
+func (m *model) peek(key uint8) (int, bool) {
+	i := m.find(key)
+	if i < 0 {
+		return 0, false
+	}
+	if m.expired(m.entries[i]) {
+		return 0, false
+	}
+	return m.entries[i].value, true
+}
+
+func (m *model) delete(key uint8) bool {
+	i := m.find(key)
+	if i < 0 {
+		return false
+	}
+	m.entries = slices.Delete(m.entries, i, i+1)
+	return true
+}
+
+func (m *model) expire(key uint8, d time.Duration) {
+	if i := m.find(key); i >= 0 {
+		m.entries[i].expireAt = time.Time{}
+		if d > 0 {
+			m.entries[i].expireAt = m.clock.Now().Add(d)
+		}
+	}
+}
+
+func (m *model) setCapacity(size int) {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	m.size = size
+	m.evict()
+}
+
+// purge 与Purge的判断一致：超过过期时间点才清理
+func (m *model) purge() int {
+	n := len(m.entries)
+	m.entries = slices.DeleteFunc(m.entries, func(e modelEntry) bool {
+		return !e.expireAt.IsZero() && m.clock.Now().After(e.expireAt)
+	})
+	return n - len(m.entries)
+}
+
+func (m *model) keys() []uint8 {
+	keys := []uint8{}
+	for _, e := range m.entries {
+		if !m.expired(e) {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+func (m *model) evict() {
+	if len(m.entries) > m.size {
+		m.entries = m.entries[:m.size]
+	}
+}
+
+// FuzzCache 将字节流解码为操作序列，同时作用于Cache和参考模型并比较结果
+func FuzzCache(f *testing.F) {
+	f.Add([]byte{3, 0, 0, 1, 10, 0, 2, 20, 0, 3, 30, 1, 1, 0, 4, 40, 7})
+	f.Add([]byte{2, 5, 0, 1, 1, 4, 1, 2, 8, 6, 0, 9, 1, 5, 1, 3, 2, 7})
+	f.Add([]byte{4, 0, 0, 1, 1, 0, 2, 2, 3, 1, 5, 8, 3, 10, 6, 4, 1, 2, 8, 0, 7})
+	f.Add([]byte{1, 3, 0, 0, 0, 5, 0, 9, 0, 2, 7, 1, 0, 9, 1, 6, 3, 4, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) < 2 {
+			return
+		}
+
+		clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+		size := int(data[0]%8) + 1
+		ttl := time.Duration(data[1]%4) * 5 * time.Millisecond
+
+		cache := New[uint8, int](size).TTL(ttl)
+		cache.now = clock.Now
+		m := &model{size: size, ttl: ttl, clock: clock}
+
+		next := func(i *int) byte {
+			if *i >= len(data) {
+				return 0
+			}
+			b := data[*i]
+			*i++
+			return b
+		}
+
+		for i := 2; i < len(data); {
+			op := next(&i) % 10
+			key := next(&i) % 8
+
+			switch op {
+			case 0: // Set
+				value := int(next(&i))
+				cache.Set(key, value)
+				m.set(key, value)
+			case 1: // Get
+				v1, ok1 := cache.Get(key)
+				v2, ok2 := m.get(key)
+				if v1 != v2 || ok1 != ok2 {
+					t.Fatalf("Get(%d) = %v, %v; 模型为 %v, %v", key, v1, ok1, v2, ok2)
+				}
+			case 2: // Peek
+				v1, ok1 := cache.Peek(key)
+				v2, ok2 := m.peek(key)
+				if v1 != v2 || ok1 != ok2 {
+					t.Fatalf("Peek(%d) = %v, %v; 模型为 %v, %v", key, v1, ok1, v2, ok2)
+				}
+			case 3: // Delete
+				if d1, d2 := cache.Delete(key), m.delete(key); d1 != d2 {
+					t.Fatalf("Delete(%d) = %v; 模型为 %v", key, d1, d2)
+				}
+			case 4: // Expire
+				d := time.Duration(next(&i)%8) * 5 * time.Millisecond
+				EntryOption[uint8, int]{key: key, owner: cache}.Expire(d)
+				m.expire(key, d)
+			case 5: // SetCapacity
+				n := int(key) - 1
+				cache.SetCapacity(n)
+				m.setCapacity(n)
+			case 6: // Purge
+				if n1, n2 := cache.Purge(), m.purge(); n1 != n2 {
+					t.Fatalf("Purge() = %d; 模型为 %d", n1, n2)
+				}
+			case 7: // Clear
+				cache.Clear()
+				m.entries = nil
+			default: // 推进时钟
+				clock.Advance(time.Duration(key) * 3 * time.Millisecond)
+			}
+
+			if err := cache.Validate(); err != nil {
+				t.Fatalf("Validate() = %v", err)
+			}
+			if s1, s2 := cache.Size(), len(m.entries); s1 != s2 {
+				t.Fatalf("Size() = %d; 模型为 %d", s1, s2)
+			}
+			if k1, k2 := cache.Keys(), m.keys(); !slices.Equal(k1, k2) {
+				t.Fatalf("Keys() = %v; 模型为 %v", k1, k2)
+			}
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package lru
+
+import "sync/atomic"
+
+// Handle 是Acquire返回的缓存项引用，使用完毕后必须调用Release
+// 持有句柄期间该项仍可能因容量淘汰从缓存中移除，但淘汰回调会推迟到最后一个句柄释放时调用，
+// 因此可以在OnEvict中安全地关闭文件、释放模型等资源；
+// 释放前该键被重新写入或删除时，仍会以被淘汰的旧值调用回调
+type Handle[V any] struct {
+	value V        // 获取时缓存项的值
+	ref   releaser // 句柄引用的缓存项，零值句柄为nil
+}
+
+// releaser 是句柄引用的缓存项
+type releaser interface {
+	release()
+}
+
+// Value 返回获取时缓存项的值
+// 持有句柄期间用Set更新该键不会改变句柄中的值
+func (h Handle[V]) Value() V {
+	return h.value
+}
+
+// Release 释放句柄，重复调用或对零值句柄调用不做任何操作
+// 该项已被淘汰且这是最后一个句柄时，会在持有缓存锁时调用淘汰回调
+func (h Handle[V]) Release() {
+	if h.ref != nil {
+		h.ref.release()
+	}
+}
+
+// handleRef 记录句柄引用的节点，保证每个句柄只释放一次
+type handleRef[K comparable, V any] struct {
+	owner    *Cache[K, V]
+	node     *node[K, V]
+	released atomic.Bool
+}
+
+func (r *handleRef[K, V]) release() {
+	if !r.released.Swap(true) {
+		r.owner.release(r.node)
+	}
+}
+
+// Acquire 获取缓存项并增加引用计数，与Get一样会更新位置、命中次数和命中统计
+// 参数 key: 要获取的缓存项键
+// 返回值: 缓存项的句柄和是否存在/有效的标志，不存在时返回零值句柄
+// 只查找缓存本身，不会调用Store或加载函数；
+// 句柄只推迟容量淘汰的回调，Delete、Clear、过期清理以及用Set替换该键都不会等待句柄释放
+func (c *Cache[K, V]) Acquire(key K) (Handle[V], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, result := c.lookup(key, true)
+	c.record(result)
+	if result != LookupHit {
+		return Handle[V]{}, false
+	}
+	e := c.items[key]
+	e.refs++
+	return Handle[V]{value: value, ref: &handleRef[K, V]{owner: c, node: e}}, true
+}
+
+// release 减少节点的引用计数，最后一个句柄释放时调用推迟的淘汰回调
+func (c *Cache[K, V]) release(e *node[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e.refs--
+	if e.refs > 0 || !e.deferred {
+		return
+	}
+	e.deferred = false
+	// 该键被取代后可能已有另一个等待释放的新节点
+	if c.deferred[e.entry.key] == e {
+		delete(c.deferred, e.entry.key)
+	}
+	c.evicted(e)
+}
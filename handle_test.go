@@ -0,0 +1,142 @@
+package lru
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 测试句柄推迟淘汰回调
+func TestAcquire(t *testing.T) {
+	t.Log("🔍 测试: 持有句柄时淘汰回调推迟到最后一次释放")
+	var closed []string
+	cache := New[string, int](2).OnEvict(func(key string, _ int, _ time.Time) {
+		closed = append(closed, key)
+	})
+
+	cache.Set("a", 1)
+	h1, ok := cache.Acquire("a")
+	if !ok || h1.Value() != 1 {
+		t.Fatalf("❌ Acquire应返回值1, 实际%v, %v", h1.Value(), ok)
+	}
+	h2, _ := cache.Acquire("a")
+
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.Set("d", 4)
+	if _, ok := cache.Peek("a"); ok || cache.Size() != 2 {
+		t.Errorf("❌ 持有句柄的项仍应从缓存中淘汰, 项数%d", cache.Size())
+	}
+	if !slices.Equal(closed, []string{"b"}) {
+		t.Errorf("❌ 只应立即回调没有句柄的项, 实际%v", closed)
+	} else {
+		t.Log("✅ 被淘汰的a仍在使用，回调被推迟")
+	}
+
+	h1.Release()
+	h1.Release()
+	if !slices.Equal(closed, []string{"b"}) {
+		t.Errorf("❌ 重复释放同一个句柄不应触发回调, 实际%v", closed)
+	}
+	h2.Release()
+	if !slices.Equal(closed, []string{"b", "a"}) {
+		t.Errorf("❌ 最后一个句柄释放后应调用回调, 实际%v", closed)
+	} else {
+		t.Log("✅ 最后一个句柄释放后调用回调")
+	}
+
+	h3, _ := cache.Acquire("d")
+	h3.Release()
+	cache.Set("e", 5)
+	cache.Set("f", 6)
+	if !slices.Equal(closed, []string{"b", "a", "c", "d"}) {
+		t.Errorf("❌ 句柄释放后应正常淘汰, 实际%v", closed)
+	}
+
+	h4, _ := cache.Acquire("f")
+	cache.Delete("f")
+	h4.Release()
+	if slices.Contains(closed, "f") {
+		t.Error("❌ Delete删除的项不应触发淘汰回调")
+	}
+
+	if h, ok := cache.Acquire("none"); ok || h.Value() != 0 {
+		t.Error("❌ 不存在的键应返回零值句柄")
+	} else {
+		h.Release()
+		t.Log("✅ 零值句柄可以安全释放")
+	}
+}
+
+// 测试并发获取和释放句柄
+func TestAcquireConcurrent(t *testing.T) {
+	t.Log("🔍 测试: 并发获取、释放和淘汰")
+	var mu sync.Mutex
+	evicted := map[int]int{}
+	cache := New[int, int](8).OnEvict(func(key, _ int, _ time.Time) {
+		mu.Lock()
+		evicted[key]++
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				key := (g*1000 + i) % 32
+				cache.Set(key, i)
+				if h, ok := cache.Acquire(key); ok {
+					h.Value()
+					h.Release()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := cache.Validate(); err != nil {
+		t.Errorf("❌ Validate() = %v", err)
+	}
+	total := 0
+	for _, n := range evicted {
+		total += n
+	}
+	t.Logf("📊 共淘汰%d次，涉及%d个键", total, len(evicted))
+}
+
+// 测试推迟回调期间重新写入该键时仍以旧值调用回调
+func TestAcquireRewrite(t *testing.T) {
+	t.Log("🔍 测试: 释放句柄时总是以被淘汰的旧值调用回调")
+	var finalized []int
+	cache := New[string, int](1).OnEvict(func(_ string, value int, _ time.Time) {
+		finalized = append(finalized, value)
+	})
+
+	cache.Set("k", 1)
+	h, _ := cache.Acquire("k")
+	cache.Set("x", 0) // "k"被淘汰，回调推迟
+	cache.Set("k", 2) // 淘汰"x"
+	cache.Delete("k")
+	h.Release()
+
+	if !slices.Equal(finalized, []int{0, 1}) {
+		t.Errorf("❌ 旧值1应在释放时被回调, 实际%v", finalized)
+	} else {
+		t.Log("✅ 重新写入和删除没有取消旧值的回调")
+	}
+
+	cache.Set("k", 3)
+	h, _ = cache.Acquire("k")
+	cache.Set("y", 0)
+	cache.Clear()
+	if len(cache.deferred) != 0 {
+		t.Errorf("❌ Clear后不应保留等待释放的节点, 实际%d个", len(cache.deferred))
+	}
+	h.Release()
+	if !slices.Equal(finalized, []int{0, 1, 3}) {
+		t.Errorf("❌ Clear后释放句柄仍应回调旧值3, 实际%v", finalized)
+	}
+}
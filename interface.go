@@ -0,0 +1,38 @@
+package lru
+
+// Interface 是所有缓存实现共同满足的接口
+// 调用方依赖该接口即可在测试和生产环境中替换不同的实现
+type Interface[K comparable, V any] interface {
+	// Set 添加或更新缓存项，返回链式调用句柄
+	Set(key K, value V) EntryOption[K, V]
+	// Get 获取缓存项的值
+	Get(key K) (V, bool)
+	// Peek 获取缓存项的值但不更新位置
+	Peek(key K) (V, bool)
+	// Delete 删除缓存项
+	Delete(key K) bool
+	// Size 返回当前缓存中的项数
+	Size() int
+	// Capacity 返回缓存容量
+	Capacity() int
+	// SetCapacity 调整缓存容量
+	SetCapacity(size int)
+	// Keys 返回所有未过期的键
+	Keys() []K
+	// Range 遍历所有未过期的缓存项
+	Range(fn func(K, V) bool)
+	// Clear 清空缓存
+	Clear()
+	// Purge 清理所有过期项，返回清理的项数
+	Purge() int
+	// Close 释放缓存占用的资源
+	Close() error
+}
+
+// 编译期检查各实现是否满足Interface
+var (
+	_ Interface[string, int] = (*Cache[string, int])(nil)
+	_ Interface[string, int] = (*TieredCache[string, int])(nil)
+	_ Interface[string, int] = (*Nop[string, int])(nil)
+	_ Interface[string, int] = (*Map[string, int])(nil)
+)
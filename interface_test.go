@@ -0,0 +1,86 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+// 测试各实现通过Interface的基本读写
+func TestInterfaceImplementations(t *testing.T) {
+	t.Log("🔍 测试: 各缓存实现满足Interface")
+	impls := map[string]Interface[string, int]{
+		"Cache":       New[string, int](3),
+		"TieredCache": NewTiered(New[string, int](3), AsTier(New[string, int](3))),
+		"Map":         NewMap[string, int](),
+	}
+
+	for name, cache := range impls {
+		cache.Set("a", 1)
+		cache.Set("b", 2).Expire(time.Hour)
+		if v, ok := cache.Get("a"); !ok || v != 1 {
+			t.Errorf("❌ %s: Get('a')失败: %v, %v", name, v, ok)
+		}
+		if !cache.Delete("a") || cache.Size() != 1 {
+			t.Errorf("❌ %s: Delete后大小应为1, 实际%d", name, cache.Size())
+		}
+		cache.Clear()
+		if cache.Size() != 0 {
+			t.Errorf("❌ %s: Clear后大小应为0", name)
+		}
+		if err := cache.Close(); err != nil {
+			t.Errorf("❌ %s: Close返回错误: %v", name, err)
+		}
+		t.Logf("✅ %s 基本操作正确", name)
+	}
+}
+
+// 测试不保存数据的缓存
+func TestNop(t *testing.T) {
+	t.Log("🔍 测试: Nop缓存")
+	var cache Interface[string, int] = NewNop[string, int]()
+
+	cache.Set("a", 1).Expire(time.Minute)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("❌ Nop缓存不应保存数据")
+	}
+	if cache.Size() != 0 || len(cache.Keys()) != 0 || cache.Purge() != 0 {
+		t.Error("❌ Nop缓存应始终为空")
+	} else {
+		t.Log("✅ Nop缓存丢弃所有写入")
+	}
+}
+
+// 测试基于map的无容量限制缓存
+func TestMap(t *testing.T) {
+	t.Log("🔍 测试: Map无容量限制缓存")
+	cache := NewMap[int, int]()
+
+	for i := 0; i < 1000; i++ {
+		cache.Set(i, i)
+	}
+	if cache.Size() != 1000 {
+		t.Errorf("❌ Map不应淘汰任何项, 实际大小%d", cache.Size())
+	} else {
+		t.Log("✅ Map保存了全部1000项")
+	}
+
+	cache.Set(-1, -1).Expire(10 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := cache.Peek(-1); ok {
+		t.Error("❌ 过期项不应被返回")
+	}
+	if n := cache.Purge(); n != 1 {
+		t.Errorf("❌ 应清理1个过期项, 实际%d个", n)
+	} else {
+		t.Log("✅ Map支持单项过期时间")
+	}
+
+	count := 0
+	cache.Range(func(k, v int) bool {
+		count++
+		return count < 10
+	})
+	if count != 10 {
+		t.Errorf("❌ Range提前终止失败, 遍历了%d项", count)
+	}
+}
@@ -0,0 +1,114 @@
+package lru
+
+// node 是LRU链表的节点，直接保存缓存项，更新缓存项时不需要重新分配或装箱
+type node[K comparable, V any] struct {
+	entry      entry[K, V]    // 缓存项
+	prev, next *node[K, V]    // 相邻节点，链表首尾指向哨兵节点
+	list       *lruList[K, V] // 所属链表，从链表删除后为nil
+	meta       *entryMeta     // 缓存项的时间和访问元数据，关闭元数据记录时为nil
+	refs       int            // 未释放的句柄数量，由缓存锁保护
+	writes     uint64         // 缓存项被替换的次数，用于判断写入Store期间该项是否被修改
+	deferred   bool           // 淘汰时仍有未释放的句柄，淘汰回调推迟到最后一个句柄释放时调用
+	superseded bool           // 推迟回调期间该键被重新写入或删除，释放时仍调用淘汰回调但不再降级
+}
+
+// Next 返回下一个节点，没有时返回nil
+func (n *node[K, V]) Next() *node[K, V] {
+	if p := n.next; n.list != nil && p != &n.list.root {
+		return p
+	}
+	return nil
+}
+
+// Prev 返回上一个节点，没有时返回nil
+func (n *node[K, V]) Prev() *node[K, V] {
+	if p := n.prev; n.list != nil && p != &n.list.root {
+		return p
+	}
+	return nil
+}
+
+// lruList 是保存缓存项的侵入式双向链表，接口与container/list一致
+// 头部是最近使用的项，尾部是最久未使用的项
+type lruList[K comparable, V any] struct {
+	root node[K, V] // 哨兵节点，root.next为头部，root.prev为尾部
+	len  int        // 节点数量，不包含哨兵节点
+}
+
+// newList 创建空链表
+func newList[K comparable, V any]() *lruList[K, V] {
+	return new(lruList[K, V]).Init()
+}
+
+// Init 清空链表
+// 原有节点不会被修改，调用者应同时丢弃对它们的引用
+func (l *lruList[K, V]) Init() *lruList[K, V] {
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	l.len = 0
+	return l
+}
+
+// Len 返回节点数量
+func (l *lruList[K, V]) Len() int { return l.len }
+
+// Front 返回头部节点，链表为空时返回nil
+func (l *lruList[K, V]) Front() *node[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Back 返回尾部节点，链表为空时返回nil
+func (l *lruList[K, V]) Back() *node[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// PushFront 在头部插入保存item的新节点并返回该节点
+func (l *lruList[K, V]) PushFront(item entry[K, V]) *node[K, V] {
+	return l.PushFrontNode(&node[K, V]{entry: item})
+}
+
+// PushFrontNode 在头部插入调用者分配的节点并返回该节点，节点不能属于任何链表
+func (l *lruList[K, V]) PushFrontNode(n *node[K, V]) *node[K, V] {
+	l.insertAfter(n, &l.root)
+	return n
+}
+
+// MoveToFront 将节点移到头部，节点不属于该链表时不做任何操作
+func (l *lruList[K, V]) MoveToFront(n *node[K, V]) {
+	if n.list != l || l.root.next == n {
+		return
+	}
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	l.len--
+	l.insertAfter(n, &l.root)
+}
+
+// Remove 从链表删除节点，节点中的缓存项保持不变
+func (l *lruList[K, V]) Remove(n *node[K, V]) {
+	if n.list != l {
+		return
+	}
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	n.prev = nil
+	n.next = nil
+	n.list = nil
+	l.len--
+}
+
+// insertAfter 将节点插入到at之后
+func (l *lruList[K, V]) insertAfter(n, at *node[K, V]) {
+	n.prev = at
+	n.next = at.next
+	n.prev.next = n
+	n.next.prev = n
+	n.list = l
+	l.len++
+}
@@ -0,0 +1,57 @@
+package lru
+
+import (
+	"slices"
+	"testing"
+)
+
+// listKeys 按从头到尾的顺序返回链表中的键
+func listKeys(l *lruList[int, int]) []int {
+	var keys []int
+	for n := l.Front(); n != nil; n = n.Next() {
+		keys = append(keys, n.entry.key)
+	}
+	return keys
+}
+
+// 测试侵入式链表的基本操作
+func TestList(t *testing.T) {
+	t.Log("🔍 测试: 侵入式链表的插入、移动和删除")
+	l := newList[int, int]()
+	if l.Front() != nil || l.Back() != nil || l.Len() != 0 {
+		t.Fatal("❌ 新链表应为空")
+	}
+
+	n1 := l.PushFront(entry[int, int]{key: 1})
+	n2 := l.PushFront(entry[int, int]{key: 2})
+	n3 := l.PushFront(entry[int, int]{key: 3})
+	if keys := listKeys(l); !slices.Equal(keys, []int{3, 2, 1}) {
+		t.Errorf("❌ 插入后顺序应为[3 2 1], 实际%v", keys)
+	}
+
+	l.MoveToFront(n1)
+	if keys := listKeys(l); !slices.Equal(keys, []int{1, 3, 2}) || l.Back() != n2 {
+		t.Errorf("❌ 移动后顺序应为[1 3 2], 实际%v", keys)
+	} else {
+		t.Log("✅ MoveToFront正确")
+	}
+
+	l.Remove(n3)
+	l.Remove(n3)
+	l.MoveToFront(n3)
+	if keys := listKeys(l); !slices.Equal(keys, []int{1, 2}) || l.Len() != 2 {
+		t.Errorf("❌ 删除后顺序应为[1 2], 实际%v", keys)
+	} else {
+		t.Log("✅ 重复删除和移动已删除的节点不影响链表")
+	}
+	if n3.entry.key != 3 || n3.Next() != nil || n3.Prev() != nil {
+		t.Error("❌ 删除的节点应保留缓存项且不再链接")
+	}
+
+	l.Init()
+	if l.Len() != 0 || l.Front() != nil {
+		t.Error("❌ Init后链表应为空")
+	} else {
+		t.Log("✅ Init清空链表")
+	}
+}
@@ -0,0 +1,263 @@
+package lru
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound 表示键在后端数据源中不存在
+// 加载函数返回该错误（或包装了该错误）时，缓存会记录一个负缓存项
+var ErrNotFound = errors.New("lru: key not found")
+
+// ErrorTTL 设置加载错误的缓存时间
+// 参数 base: 第一次失败后缓存错误的时间，为0时不缓存错误
+// 参数 max: 连续失败时按指数退避增长的上限，小于等于base时不退避
+// 返回缓存实例本身，支持链式调用
+func (c *Cache[K, V]) ErrorTTL(base, max time.Duration) *Cache[K, V] {
+	c.mu.Lock()
+	c.errorTTL = base
+	c.errorMaxTTL = max
+	c.mu.Unlock()
+	return c
+}
+
+// ServeStale 设置加载失败时是否继续返回上一次成功加载的值
+// 参数 enable: 为true时，错误缓存期间GetOrLoad和Get返回旧值而不是错误
+// 返回缓存实例本身，支持链式调用
+// 开启后已过期的值不会被Purge、清理协程和查找删除，直到被重新加载、删除或容量淘汰，
+// 因此会继续占用容量
+func (c *Cache[K, V]) ServeStale(enable bool) *Cache[K, V] {
+	c.mu.Lock()
+	c.serveStale = enable
+	c.mu.Unlock()
+	return c
+}
+
+// GetOrLoad 获取缓存项，不存在或已过期时调用loader加载并写入缓存
+// 参数 key: 要获取的缓存项键
+// 参数 loader: 加载函数，在不持有锁的情况下调用
+// 返回值: 缓存项的值和错误
+// 加载返回ErrNotFound时写入负缓存项，后续调用直接返回ErrNotFound；
+// 设置了ErrorTTL时其他错误也会被缓存，在退避时间内直接返回给后续调用者；
+// 同时开启了ServeStale且旧值未被容量淘汰时，返回旧值而不是错误；
+// 加载期间该键被写入或删除，或缓存已被置顶项占满时返回加载结果但不写入缓存
+func (c *Cache[K, V]) GetOrLoad(key K, loader func(K) (V, error)) (V, error) {
+	value, err, miss, ok := c.cached(key)
+	if ok {
+		return value, err
+	}
+	return c.load(key, loader, miss)
+}
+
+// missState 是未命中时记录的加载前状态
+type missState[K comparable, V any] struct {
+	prev *entry[K, V] // 加载前该键对应的已过期项，用于保留旧值和失败次数，nil表示不存在
+	gen  uint64       // 加载开始时该键的写入次数
+}
+
+// inflight 记录一个键正在进行的加载，只在该键有加载进行时存在
+type inflight struct {
+	loads  int    // 正在进行的加载数量
+	writes uint64 // 加载期间该键被写入或删除的次数
+}
+
+// cached 从缓存中获取键对应的值或缓存的错误，并更新命中统计
+// 参数 key: 要获取的缓存项键
+// 返回值: 值、缓存的错误（负缓存项返回ErrNotFound，已关闭时返回ErrClosed）、
+// 未命中时的加载前状态以及缓存中是否有有效项；
+// 未命中时登记一次加载，调用者必须通过load或cancel结束该加载
+func (c *Cache[K, V]) cached(key K) (V, error, missState[K, V], bool) {
+	if value, ok := c.hit(key); ok {
+		return value, nil, missState[K, V]{}, true
+	}
+
+	var zero V
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return zero, ErrClosed, missState[K, V]{}, true
+	}
+	e, ok := c.items[key]
+	if !ok {
+		// 尚未写入Store的脏项比Store中的值更新，直接放回缓存而不调用loader
+		if value, ok := c.revive(key); ok {
+			c.hits.Add(1)
+			return value, nil, missState[K, V]{}, true
+		}
+		c.misses.Add(1)
+		return zero, nil, missState[K, V]{gen: c.startLoad(key)}, false
+	}
+
+	item := &e.entry
+	if item.expired(c.now()) {
+		// 已过期，返回旧项的副本，不需要保留时删除
+		prev := *item
+		if !c.retain(item) {
+			c.removeElement(e)
+		}
+		c.misses.Add(1)
+		return zero, nil, missState[K, V]{prev: &prev, gen: c.startLoad(key)}, false
+	}
+
+	c.touch(e)
+	switch {
+	case item.missing:
+		c.negativeHits.Add(1)
+		return zero, ErrNotFound, missState[K, V]{}, true
+	case !item.hasValue():
+		c.misses.Add(1)
+		return zero, item.err, missState[K, V]{}, true
+	}
+	e.access(c.now())
+	c.hits.Add(1)
+	return item.value, nil, missState[K, V]{}, true
+}
+
+// hit 在读锁下查找有效值，命中时记录到读缓冲区而不立即移动位置
+// 参数 key: 要获取的缓存项键
+// 返回值: 缓存项的值和是否命中，未命中（包括过期、负缓存和错误项）时由调用者在写锁下处理
+func (c *Cache[K, V]) hit(key K) (V, bool) {
+	var (
+		value V
+		full  bool
+	)
+	c.mu.RLock()
+	e, ok := c.items[key]
+	if ok {
+		item := &e.entry
+		now := c.now()
+		if ok = item.hasValue() && !item.expired(now); ok {
+			value = item.value
+			e.access(now)
+			full = c.reads.push(e)
+		}
+	}
+	c.mu.RUnlock()
+
+	// 条带写满时尝试立即应用，锁被占用时交给下一个写操作
+	if full && c.mu.TryLock() {
+		c.drainReads()
+		c.mu.Unlock()
+	}
+	if ok {
+		c.hits.Add(1)
+	}
+	return value, ok
+}
+
+// load 在不持有锁的情况下调用loader，并将结果写入缓存
+// 参数 miss: cached记录的加载前状态
+// 加载期间该键被写入或删除时只返回加载结果，不写入缓存，避免旧值覆盖更新的值或恢复已删除的键；
+// 该键有尚未写入Store的已删除脏项时同样不写入，避免缓存保留Store中即将被覆盖的旧值；
+// 缓存被置顶项占满时也不写入，不会插入新项后立即将其淘汰
+func (c *Cache[K, V]) load(key K, loader func(K) (V, error), miss missState[K, V]) (V, error) {
+	var zero V
+
+	// loader发生panic时同样结束加载
+	loaded := false
+	defer func() {
+		if !loaded {
+			c.cancel(key, miss)
+		}
+	}()
+	value, err := loader(key)
+	loaded = true
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 加载期间缓存已关闭、该键已被写入或缓存已被置顶项占满，不再写入结果
+	if !c.endLoad(key, miss.gen) || c.closed || c.admit(key, false) != nil {
+		return value, err
+	}
+	prev := miss.prev
+	switch {
+	case err == nil:
+		c.set(key, value)
+		return value, nil
+	case errors.Is(err, ErrNotFound):
+		c.setMissing(key, 0)
+	case c.errorTTL > 0:
+		if item := c.setError(key, err, prev); item.stale {
+			return item.value, nil
+		}
+	}
+	return zero, err
+}
+
+// startLoad 登记key的一次加载
+// 返回值: 加载开始时该键的写入次数，加载结束时传给endLoad
+// 调用前必须持有锁
+func (c *Cache[K, V]) startLoad(key K) uint64 {
+	f, ok := c.loading[key]
+	if !ok {
+		if c.loading == nil {
+			c.loading = make(map[K]*inflight)
+		}
+		f = &inflight{}
+		c.loading[key] = f
+	}
+	f.loads++
+	return f.writes
+}
+
+// endLoad 结束key的一次加载
+// 参数 gen: startLoad返回的写入次数
+// 返回值: 加载结果能否写入缓存，加载期间该键被写入或删除，或该键有等待写入Store的脏项时返回false
+// 调用前必须持有锁，且必须在写入加载结果之前调用
+func (c *Cache[K, V]) endLoad(key K, gen uint64) bool {
+	f := c.loading[key]
+	if f.loads--; f.loads == 0 {
+		delete(c.loading, key)
+	}
+	_, queued := c.pending[key]
+	return f.writes == gen && !queued
+}
+
+// cancel 结束cached登记的加载而不写入结果
+// 调用前不能持有锁
+func (c *Cache[K, V]) cancel(key K, miss missState[K, V]) {
+	c.mu.Lock()
+	c.endLoad(key, miss.gen)
+	c.mu.Unlock()
+}
+
+// setError 缓存一次加载错误
+// 参数 prev: 加载前该键对应的（已过期）项，nil表示不存在
+// 返回值: 写入的错误项
+// 调用前必须持有锁
+func (c *Cache[K, V]) setError(key K, err error, prev *entry[K, V]) entry[K, V] {
+	item := entry[K, V]{key: key, err: err, failures: 1}
+	if prev != nil && prev.err != nil {
+		item.failures = prev.failures + 1
+	}
+	if c.serveStale && prev != nil && prev.hasValue() {
+		item.value = prev.value
+		item.stale = true
+	}
+	item.expireAt = c.now().Add(c.backoff(item.failures))
+
+	c.put(item)
+	return item
+}
+
+// retain 报告已过期的项是否应留在缓存中，供下一次加载失败时返回旧值或计算退避时间
+// 开启ServeStale时保留带值的项，设置了ErrorTTL时保留错误项，保留的项仍会被容量淘汰
+// 调用前必须持有锁
+func (c *Cache[K, V]) retain(item *entry[K, V]) bool {
+	return (c.serveStale && item.hasValue()) || (c.errorTTL > 0 && item.err != nil)
+}
+
+// backoff 计算第n次连续失败后的错误缓存时间
+// 调用前必须持有锁
+func (c *Cache[K, V]) backoff(failures int) time.Duration {
+	d := c.errorTTL
+	for i := 1; i < failures && d < c.errorMaxTTL; i++ {
+		d *= 2
+	}
+	if c.errorMaxTTL > c.errorTTL && d > c.errorMaxTTL {
+		d = c.errorMaxTTL
+	}
+	return d
+}
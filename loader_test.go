@@ -0,0 +1,193 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 测试GetOrLoad基本加载和负缓存
+func TestGetOrLoad(t *testing.T) {
+	t.Log("🔍 测试: GetOrLoad加载和负缓存")
+	cache := New[string, int](3).NegativeTTL(time.Minute)
+
+	calls := 0
+	loader := func(k string) (int, error) {
+		calls++
+		if k == "missing" {
+			return 0, ErrNotFound
+		}
+		return len(k), nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, err := cache.GetOrLoad("abc", loader); err != nil || v != 3 {
+			t.Fatalf("❌ 加载'abc'失败: %v, %v", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("❌ 命中缓存后不应再次加载, 实际加载%d次", calls)
+	} else {
+		t.Log("✅ 第二次调用命中缓存")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetOrLoad("missing", loader); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("❌ 期望ErrNotFound, 实际 %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("❌ 负缓存命中后不应再次加载, 实际加载%d次", calls)
+	} else {
+		t.Log("✅ ErrNotFound被记录为负缓存项")
+	}
+
+	if _, result := cache.Lookup("missing"); result != LookupNegative {
+		t.Errorf("❌ 'missing'应为负缓存项, 实际 %v", result)
+	}
+}
+
+// 测试加载错误缓存和指数退避
+func TestGetOrLoadErrorBackoff(t *testing.T) {
+	t.Log("🔍 测试: 加载错误缓存和指数退避")
+	cache := New[string, int](3).ErrorTTL(20*time.Millisecond, 50*time.Millisecond)
+
+	errBoom := errors.New("boom")
+	calls := 0
+	loader := func(string) (int, error) {
+		calls++
+		return 0, errBoom
+	}
+
+	if _, err := cache.GetOrLoad("a", loader); !errors.Is(err, errBoom) {
+		t.Fatalf("❌ 期望返回加载错误, 实际 %v", err)
+	}
+	if _, err := cache.GetOrLoad("a", loader); !errors.Is(err, errBoom) || calls != 1 {
+		t.Fatalf("❌ 错误缓存期间应直接返回错误: err=%v, calls=%d", err, calls)
+	}
+	if _, result := cache.Lookup("a"); result != LookupError {
+		t.Errorf("❌ 'a'应为错误项, 实际 %v", result)
+	}
+	t.Log("✅ 错误被缓存并返回给后续调用者")
+
+	// 第一次窗口过期后再次失败，窗口翻倍
+	time.Sleep(30 * time.Millisecond)
+	cache.GetOrLoad("a", loader)
+	if calls != 2 {
+		t.Fatalf("❌ 错误窗口过期后应重新加载, 实际加载%d次", calls)
+	}
+	time.Sleep(30 * time.Millisecond)
+	cache.GetOrLoad("a", loader)
+	if calls != 2 {
+		t.Errorf("❌ 第二次失败后退避时间应加倍, 实际加载%d次", calls)
+	} else {
+		t.Log("✅ 连续失败后退避时间加倍")
+	}
+
+	if d := cache.backoff(10); d != 50*time.Millisecond {
+		t.Errorf("❌ 退避时间应限制在上限内, 实际 %v", d)
+	}
+}
+
+// 测试加载失败时继续返回旧值
+func TestGetOrLoadServeStale(t *testing.T) {
+	t.Log("🔍 测试: 加载失败时返回旧值")
+	cache := New[string, int](3).ErrorTTL(time.Minute, 0).ServeStale(true)
+
+	cache.Set("a", 1).Expire(20 * time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+
+	calls := 0
+	loader := func(string) (int, error) {
+		calls++
+		return 0, errors.New("unavailable")
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, err := cache.GetOrLoad("a", loader); err != nil || v != 1 {
+			t.Fatalf("❌ 应返回旧值1, 实际 %v, %v", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("❌ 错误缓存期间不应重新加载, 实际加载%d次", calls)
+	}
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Errorf("❌ Get应返回旧值: %v, %v", v, ok)
+	} else {
+		t.Log("✅ 错误缓存期间继续返回旧值")
+	}
+}
+
+// 测试Purge和清理协程保留旧值和失败次数
+func TestServeStaleSurvivesPurge(t *testing.T) {
+	t.Log("🔍 测试: Purge后仍能返回旧值并继续退避")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cache := New[string, int](3).ErrorTTL(time.Second, time.Minute).ServeStale(true)
+	cache.now = clock.Now
+
+	errBoom := errors.New("boom")
+	loader := func(string) (int, error) { return 0, errBoom }
+
+	cache.SetWithTTL("a", 1, 5*time.Millisecond)
+	clock.Advance(10 * time.Millisecond)
+	cache.Purge()
+	if v, err := cache.GetOrLoad("a", loader); err != nil || v != 1 {
+		t.Fatalf("❌ Purge后应返回旧值1, 实际 %v, %v", v, err)
+	}
+	t.Log("✅ Purge没有删除旧值")
+
+	// 错误窗口过期并被清理后，下一次失败应使用加倍的退避时间
+	clock.Advance(2 * time.Second)
+	cache.Purge()
+	if v, err := cache.GetOrLoad("a", loader); err != nil || v != 1 {
+		t.Fatalf("❌ 再次失败后应返回旧值1, 实际 %v, %v", v, err)
+	}
+	if ttl, _ := cache.TTLOf("a"); ttl != 2*time.Second {
+		t.Errorf("❌ 第二次失败的退避时间应为2s, 实际%v", ttl)
+	} else {
+		t.Log("✅ 失败次数在Purge后保留，退避时间加倍")
+	}
+
+	plain := New[string, int](3).ErrorTTL(time.Second, time.Minute)
+	plain.now = clock.Now
+	plain.GetOrLoad("b", loader)
+	clock.Advance(2 * time.Second)
+	plain.Purge()
+	plain.GetOrLoad("b", loader)
+	if ttl := plain.items["b"].entry.expireAt.Sub(clock.Now()); ttl != 2*time.Second {
+		t.Errorf("❌ 未开启ServeStale时也应保留失败次数, 退避时间%v", ttl)
+	}
+}
+
+// 测试加载期间其他键的写入不影响加载结果的缓存
+func TestGetOrLoadOtherKeyWrite(t *testing.T) {
+	t.Log("🔍 测试: 加载期间写入同一键锁条带的其他键")
+	cache := New[string, int](10)
+
+	// 选择与"x"共用键锁条带的键
+	other := "y"
+	for i := 0; stripe(other) != stripe("x"); i++ {
+		other = fmt.Sprintf("y%d", i)
+	}
+
+	calls := 0
+	loader := func(string) (int, error) {
+		calls++
+		cache.Set(other, calls)
+		return 1, nil
+	}
+	for i := 0; i < 3; i++ {
+		if v, err := cache.GetOrLoad("x", loader); err != nil || v != 1 {
+			t.Fatalf("❌ 加载'x'失败: %v, %v", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("❌ 其他键的写入不应丢弃加载结果, 实际加载%d次", calls)
+	} else {
+		t.Log("✅ 加载结果被缓存")
+	}
+	if len(cache.loading) != 0 {
+		t.Errorf("❌ 加载结束后不应保留加载记录: %v", cache.loading)
+	}
+}
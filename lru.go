@@ -1,25 +1,140 @@
 package lru
 
 import (
-	"container/list"
+	"errors"
 	"fmt"
+	"log/slog"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
+	"weak"
 )
 
 // DefaultCacheSize 是缓存大小的默认值
 const DefaultCacheSize = 10
 
+// ErrClosed 表示缓存已经关闭
+var ErrClosed = errors.New("lru: cache closed")
+
 // Cache 是线程安全的LRU缓存，支持过期时间和自动清理
 type Cache[K comparable, V any] struct {
-	mu              sync.RWMutex        // 读写互斥锁，保证并发安全
-	items           map[K]*list.Element // 存储键到链表节点的映射，用于O(1)时间复杂度查找
-	list            *list.List          // 双向链表，用于维护LRU顺序
-	size            int                 // 缓存的最大容量
-	ttl             time.Duration       // 缓存项的默认过期时间
-	cleanerStopCh   chan struct{}       // 用于停止清理协程的信号通道
-	cleanerInterval time.Duration       // 自动清理的时间间隔
+	mu              sync.RWMutex               // 读写互斥锁，保证并发安全
+	items           map[K]*node[K, V]          // 存储键到链表节点的映射，用于O(1)时间复杂度查找
+	list            *lruList[K, V]             // 双向链表，用于维护LRU顺序
+	size            int                        // 缓存的最大容量
+	ttl             time.Duration              // 缓存项的默认过期时间
+	cleanerStopCh   chan struct{}              // 用于停止清理协程的信号通道，为nil时清理协程未运行
+	cleanerCleanup  runtime.Cleanup            // 缓存被回收时停止清理协程
+	cleaner         *cleanerState              // 清理协程的运行状态，为nil时未启动过清理协程
+	logger          *slog.Logger               // 记录后台协程异常的日志记录器，为nil时使用slog.Default()
+	cleanerInterval time.Duration              // 自动清理的时间间隔
+	negativeTTL     time.Duration              // 负缓存项的默认过期时间
+	hits            atomic.Uint64              // 命中次数
+	misses          atomic.Uint64              // 未命中次数
+	negativeHits    atomic.Uint64              // 命中负缓存项的次数
+	errorTTL        time.Duration              // 加载错误的初始缓存时间，为0时不缓存错误
+	errorMaxTTL     time.Duration              // 加载错误缓存时间的退避上限
+	serveStale      bool                       // 加载失败时是否继续返回上一次成功加载的值
+	store           Store[K, V]                // 绑定的后端存储，为nil时不读写后端
+	keyLocks        [keyLockStripes]sync.Mutex // 串行化同一个键的Store写入，不持有缓存锁时获取
+	loading         map[K]*inflight            // 正在加载的键，加载期间该键被写入时丢弃加载结果
+	writeBehind     bool                       // 是否启用延迟写入，启用后Set只标记脏项
+	flushSize       int                        // 脏项达到该数量时触发批量写入
+	flushInterval   time.Duration              // 定时批量写入的时间间隔
+	flushStopCh     chan struct{}              // 用于停止写入协程的信号通道
+	flushCh         chan struct{}              // 脏项数量达到阈值或有脏项被删除时通知写入协程
+	flushMu         sync.Mutex                 // 串行化写入脏项和Store删除，在缓存锁之前获取
+	dirty           int                        // 当前脏项数量
+	pending         map[K]*node[K, V]          // 已从缓存删除但尚未写入Store的脏项
+	flushErr        error                      // 后台写入失败等尚未报告的错误
+	onEvict         func(K, V, time.Time)      // 容量淘汰时的回调函数
+	onDemote        func(K, V, time.Time)      // 两级缓存的降级回调，与onEvict一起调用，跳过已被取代的旧值
+	wal             *wal                       // 预写日志，为nil时不记录操作
+	now             func() time.Time           // 获取当前时间，默认为time.Now，测试时可替换为假时钟
+	closed          bool                       // 是否已经关闭
+	reads           *readBuffer[node[K, V]]    // 读锁下命中的访问记录，在写锁下批量移到最近使用位置
+	cost            int64                      // 所有缓存项的成本之和
+	maxCost         int64                      // 成本上限，为0时不限制
+	prioritized     int                        // 优先级不为0的缓存项数量，为0时直接淘汰最久未使用的项
+	tags            map[string]map[K]struct{}  // 标签到键集合的索引
+	untracked       bool                       // 是否关闭新缓存项的元数据记录
+	updatePolicy    UpdatePolicy               // 更新已有项时过期时间的计算方式
+	pinned          int                        // 置顶项数量
+	maxPinned       int                        // 置顶项数量上限，为0时以容量为上限
+	deferred        map[K]*node[K, V]          // 淘汰回调推迟到句柄释放的节点，该键被重新写入或删除时移出
+}
+
+// Stats 是缓存的命中统计信息
+type Stats struct {
+	Hits         uint64 // 命中有效值的次数
+	Misses       uint64 // 未命中的次数（不包含负缓存命中）
+	NegativeHits uint64 // 命中负缓存项的次数
+}
+
+// LookupResult 表示Lookup的查找结果
+type LookupResult int
+
+const (
+	// LookupMiss 表示缓存中没有该键，或该键已过期
+	LookupMiss LookupResult = iota
+	// LookupHit 表示命中了有效值
+	LookupHit
+	// LookupNegative 表示命中了负缓存项，即该键已知不存在
+	LookupNegative
+	// LookupError 表示命中了缓存的加载错误，且没有可用的旧值
+	LookupError
+)
+
+const (
+	maxCleanerRestarts = 5           // 清理协程连续发生panic后的最大重启次数，完成一次清理后重新计数
+	maxCleanerBackoff  = time.Minute // 清理协程重启前等待时间的上限
+)
+
+// CleanerHealth 是自动清理器的运行状态
+type CleanerHealth struct {
+	Running   bool      // 清理协程是否在运行，包括等待重启的期间
+	LastRun   time.Time // 最近一次完成清理的时间
+	LastError error     // 最近一次panic对应的错误
+	Restarts  int       // 发生panic后重启的总次数
+}
+
+// cleanerState 是缓存和清理协程共享的运行状态
+// 清理协程通过它报告状态，而不持有缓存的强引用
+type cleanerState struct {
+	mu       sync.Mutex
+	health   CleanerHealth
+	failures int // 最近一次完成清理之后连续重启的次数
+}
+
+// ran 记录一次完成的清理，并重新开始计算连续重启次数
+func (s *cleanerState) ran(t time.Time) {
+	s.mu.Lock()
+	s.health.LastRun = t
+	s.failures = 0
+	s.mu.Unlock()
+}
+
+// restart 记录一次panic，并在连续重启次数未达到上限时增加重启次数
+// 返回值: 连续重启次数，以及是否可以重启
+func (s *cleanerState) restart(err error) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.health.LastError = err
+	if s.failures >= maxCleanerRestarts {
+		return s.failures, false
+	}
+	s.failures++
+	s.health.Restarts++
+	return s.failures, true
+}
+
+// stop 记录清理协程已停止
+func (s *cleanerState) stop() {
+	s.mu.Lock()
+	s.health.Running = false
+	s.mu.Unlock()
 }
 
 // entry 表示缓存中的条目
@@ -27,12 +142,39 @@ type entry[K comparable, V any] struct {
 	key      K         // 缓存项的键
 	value    V         // 缓存项的值
 	expireAt time.Time // 缓存项的过期时间点，零值表示永不过期
+	missing  bool      // 是否为负缓存项，负缓存项只记录键不存在，不携带值
+	err      error     // 加载失败时缓存的错误，在过期前返回给后续调用者
+	failures int       // 连续加载失败的次数，用于计算退避时间
+	stale    bool      // 错误项是否保留了上一次成功加载的值
+	dirty    bool      // 延迟写入模式下尚未写入Store的脏项
+	cost     int64     // 成本，为0时按1计算
+	priority int       // 优先级，淘汰时优先淘汰优先级低的项
+	tags     []string  // 标签，可通过InvalidateTag批量删除
+	pinned   bool      // 是否置顶，置顶的项不会因容量或成本上限被淘汰
+}
+
+// hasValue 报告该项是否携带可返回给调用者的值
+// 负缓存项和不带旧值的错误项都不携带值
+func (e entry[K, V]) hasValue() bool {
+	return !e.missing && (e.err == nil || e.stale)
+}
+
+// expired 报告该项在now时刻是否已过期，到达过期时间点即视为过期
+func (e entry[K, V]) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// EntryOption 提供单个缓存项的链式操作，由各缓存实现的Set方法返回
+// EntryOption是值类型，返回和链式调用都不会分配内存
+type EntryOption[K comparable, V any] struct {
+	key   K          // 操作的缓存项键
+	owner expirer[K] // 指向所属缓存的引用
 }
 
-// entryOption 提供单个缓存项的链式操作
-type entryOption[K comparable, V any] struct {
-	key   K            // 操作的缓存项键
-	cache *Cache[K, V] // 指向所属缓存的引用
+// expirer 是可以修改单个缓存项过期时间的缓存实现
+type expirer[K comparable] interface {
+	// expire 修改缓存项的过期时间，duration小于等于0表示永不过期
+	expire(key K, duration time.Duration)
 }
 
 // New 创建指定大小的缓存
@@ -43,10 +185,11 @@ func New[K comparable, V any](size int) *Cache[K, V] {
 		size = DefaultCacheSize // 使用默认缓存大小
 	}
 	return &Cache[K, V]{
-		size:          size,
-		items:         make(map[K]*list.Element),
-		list:          list.New(),
-		cleanerStopCh: make(chan struct{}),
+		size:  size,
+		items: make(map[K]*node[K, V]),
+		list:  newList[K, V](),
+		now:   time.Now,
+		reads: newReadBuffer[node[K, V]](),
 	}
 }
 
@@ -60,101 +203,222 @@ func (c *Cache[K, V]) TTL(duration time.Duration) *Cache[K, V] {
 	return c
 }
 
+// NegativeTTL 设置负缓存项的默认过期时间
+// 参数 duration: SetMissing未指定过期时间时使用的生存时间
+// 返回缓存实例本身，支持链式调用
+func (c *Cache[K, V]) NegativeTTL(duration time.Duration) *Cache[K, V] {
+	c.mu.Lock()
+	c.negativeTTL = duration
+	c.mu.Unlock()
+	return c
+}
+
+// OnEvict 设置容量淘汰时的回调函数
+// 参数 fn: 因超出容量被淘汰的项会以键、值和过期时间点（零值表示永不过期）调用fn，
+// 已过期的项和负缓存项不会触发回调，为nil时取消回调；
+// 被淘汰的项仍有Acquire返回的未释放句柄时，回调推迟到最后一个句柄释放时调用，
+// 即使该键在此期间被重新写入或删除，也会以被淘汰时的值调用
+// 返回缓存实例本身，支持链式调用
+// 注意: fn在持有缓存锁时调用，不能再调用该缓存的方法，否则会死锁
+func (c *Cache[K, V]) OnEvict(fn func(key K, value V, expireAt time.Time)) *Cache[K, V] {
+	c.mu.Lock()
+	c.onEvict = fn
+	c.mu.Unlock()
+	return c
+}
+
+// Logger 设置记录后台协程异常的日志记录器
+// 参数 l: 日志记录器，为nil时使用slog.Default()
+// 返回缓存实例本身，支持链式调用
+// 注意: 应在Cleaner之前调用，已运行的清理协程继续使用原来的记录器
+func (c *Cache[K, V]) Logger(l *slog.Logger) *Cache[K, V] {
+	c.mu.Lock()
+	c.logger = l
+	c.mu.Unlock()
+	return c
+}
+
 // Cleaner 设置自动清理过期项的时间间隔
 // 参数 interval: 清理过期项的时间间隔
 // 返回缓存实例本身，支持链式调用
-// 注意: 使用此方法后，不再使用缓存时应调用Close方法停止清理goroutine，
-// 否则可能导致资源泄漏。推荐使用defer cache.Close()
+// 清理协程只持有缓存的弱引用，缓存不再被引用时可以被垃圾回收，回收后清理协程自动退出；
+// 不再使用缓存时仍应调用Close方法及时停止清理goroutine。推荐使用defer cache.Close()
+// 清理过程中发生panic时会记录到Logger并按指数退避重启，连续重启次数达到上限后停止清理，
+// 运行状态可通过CleanerHealth查看
 func (c *Cache[K, V]) Cleaner(interval time.Duration) *Cache[K, V] {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.closed {
+		return c
+	}
 	c.startCleaner(interval)
+	return c
+}
 
-	// 设置finalizer，防止用户忘记调用Close方法
-	runtime.SetFinalizer(c, func(c *Cache[K, V]) {
-		// 避免在finalizer中持有对象引用，创建一个本地副本
-		stopCh := c.cleanerStopCh
-		if stopCh != nil {
-			close(stopCh)
-		}
-	})
+// CleanerHealth 返回自动清理器的运行状态
+// 未调用过Cleaner时返回零值
+func (c *Cache[K, V]) CleanerHealth() CleanerHealth {
+	c.mu.RLock()
+	state := c.cleaner
+	c.mu.RUnlock()
 
-	return c
+	if state == nil {
+		return CleanerHealth{}
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return state.health
 }
 
 // startCleaner 启动自动清理器
 // 参数 interval: 清理的时间间隔
 // 会先停止现有的清理器（如果有），然后启动新的清理协程
+// 调用前必须持有锁
 func (c *Cache[K, V]) startCleaner(interval time.Duration) {
-	// 停止现有的清理器
-	if c.cleanerStopCh != nil {
-		close(c.cleanerStopCh)
+	c.stopCleaner()
+
+	logger := c.logger
+	if logger == nil {
+		logger = slog.Default()
 	}
 
 	c.cleanerInterval = interval
 	c.cleanerStopCh = make(chan struct{})
+	c.cleaner = &cleanerState{health: CleanerHealth{Running: true}}
+	// 缓存被回收时关闭信号通道，清理协程无需等到下一次触发才发现缓存已被回收
+	c.cleanerCleanup = runtime.AddCleanup(c, func(stopCh chan struct{}) {
+		close(stopCh)
+	}, c.cleanerStopCh)
 
-	go c.cleanerLoop()
+	go cleanerLoop(weak.Make(c), c.cleanerStopCh, interval, c.cleaner, logger)
+}
+
+// stopCleaner 停止自动清理器（如果有）
+// 调用前必须持有锁
+func (c *Cache[K, V]) stopCleaner() {
+	if c.cleanerStopCh == nil {
+		return
+	}
+	c.cleanerCleanup.Stop()
+	close(c.cleanerStopCh)
+	c.cleanerStopCh = nil
+	c.cleaner.stop()
 }
 
 // cleanerLoop 定时清理过期元素
 // 内部使用，作为协程运行，会定期调用Purge方法清理过期项
-// 支持panic恢复，确保清理协程不会意外终止
-func (c *Cache[K, V]) cleanerLoop() {
+// 只持有缓存的弱引用，缓存已被回收或收到停止信号时退出；
+// 清理过程中发生panic时按指数退避重启，连续重启maxCleanerRestarts次后再发生panic则退出，
+// 两次panic之间完成过清理时重新计数
+func cleanerLoop[K comparable, V any](cache weak.Pointer[Cache[K, V]], stopCh <-chan struct{}, interval time.Duration, state *cleanerState, logger *slog.Logger) {
+	defer state.stop()
+
+	for {
+		err := runCleaner(cache, stopCh, interval, state)
+		if err == nil {
+			return
+		}
+
+		restarts, ok := state.restart(err)
+		if !ok {
+			logger.Error("lru: cleaner stopped after too many restarts", "restarts", restarts, "error", err)
+			return
+		}
+
+		backoff := min(interval<<(restarts-1), maxCleanerBackoff)
+		logger.Warn("lru: cleaner panicked, restarting", "error", err, "restarts", restarts, "backoff", backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-stopCh:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// runCleaner 运行清理循环，直到缓存被回收或收到停止信号
+// 返回值: 清理过程中发生panic时返回对应的错误，正常退出时返回nil
+func runCleaner[K comparable, V any](cache weak.Pointer[Cache[K, V]], stopCh <-chan struct{}, interval time.Duration, state *cleanerState) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("缓存清理协程崩溃: %v\n", r)
-			// 可选：重启清理器
-			go c.cleanerLoop()
+			err = fmt.Errorf("lru: cleaner panic: %v", r)
 		}
 	}()
 
-	ticker := time.NewTicker(c.cleanerInterval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
+			c := cache.Value()
+			if c == nil {
+				return nil
+			}
 			c.Purge()
+			state.ran(time.Now())
 
-		case <-c.cleanerStopCh:
-			return
+		case <-stopCh:
+			return nil
 		}
 	}
 }
 
-// Close 停止自动清理
-// 如果清理器正在运行，则停止它
+// Close 关闭缓存
+// 如果清理器或写入协程正在运行，则停止它们，将所有脏项写入Store，关闭预写日志并释放所有缓存项
+// 关闭后Set和SetMissing不再生效，Get和Peek始终未命中，Try*系列方法、GetOrLoad、
+// Flush和Checkpoint返回ErrClosed
 // 当不再使用缓存时，应当调用此方法释放资源
 // 建议使用defer语句确保资源被释放: defer cache.Close()
-func (c *Cache[K, V]) Close() {
+// 返回值: 写入脏项或关闭预写日志时的错误，写入失败的脏项会被丢弃；重复调用返回nil
+func (c *Cache[K, V]) Close() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
 
-	if c.cleanerStopCh != nil {
-		close(c.cleanerStopCh)
-		c.cleanerStopCh = nil
+	c.stopCleaner()
+	if c.flushStopCh != nil {
+		close(c.flushStopCh)
+		c.flushStopCh = nil
 	}
+	c.mu.Unlock()
+
+	// 已关闭的缓存不再接受写入，在不持有缓存锁时写入剩余脏项
+	c.flushMu.Lock()
+	err := c.flush()
+	c.flushMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// 取消finalizer
-	runtime.SetFinalizer(c, nil)
+	err = errors.Join(err, c.flushErr, c.closeWAL())
+	c.flushErr = nil
+	c.pending = nil
+	c.reset()
+	return err
 }
 
 // Purge 清理所有过期项，返回清理的项数
-// 线程安全，会遍历缓存中的所有项并删除已过期的
+// 线程安全，会遍历缓存中的所有项并删除已过期的；
+// 开启ServeStale时保留已过期的值，设置了ErrorTTL时保留已过期的错误项，供下一次加载使用
 // 返回值: 清理的项数
 func (c *Cache[K, V]) Purge() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	now := time.Now()
+	now := c.now()
 	count := 0
 
 	for e := c.list.Front(); e != nil; {
 		next := e.Next()
-		item := e.Value.(entry[K, V])
-		if !item.expireAt.IsZero() && now.After(item.expireAt) {
+		item := &e.entry
+		if !item.expireAt.IsZero() && now.After(item.expireAt) && !c.retain(item) {
 			c.removeElement(e)
 			count++
 		}
@@ -167,114 +431,274 @@ func (c *Cache[K, V]) Purge() int {
 // Set 添加或更新缓存项，返回链式调用句柄
 // 参数 key: 缓存项的键
 // 参数 value: 缓存项的值
-// 返回值: 指向该缓存项的句柄，可用于进一步设置过期时间
-// 如果添加新项导致缓存超出容量，会删除最久未使用的项
-func (c *Cache[K, V]) Set(key K, value V) *entryOption[K, V] {
-	c.mu.Lock()
+// 返回值: 该缓存项的句柄，可用于进一步设置过期时间
+// 如果添加新项导致缓存超出容量，会删除最久未使用的项，缓存被置顶项占满时不会写入新键
+// 绑定了Store时会先同步写入Store，写入失败时缓存不变，需要错误信息请使用TrySet
+func (c *Cache[K, V]) Set(key K, value V) EntryOption[K, V] {
+	c.TrySet(key, value)
+	return EntryOption[K, V]{key: key, owner: c}
+}
+
+// SetWithTTL 添加或更新缓存项，并在同一次加锁中设置过期时间
+// 参数 key: 缓存项的键
+// 参数 value: 缓存项的值
+// 参数 ttl: 过期时间，如果为0或负值则表示永不过期，不使用TTL设置的默认值
+// 返回值: 缓存已关闭时返回ErrClosed，缓存被置顶项占满时返回ErrFull，以及同步写入Store的错误，
+// 出错时缓存保持不变
+// 与Set(key, value).Expire(ttl)相比只加锁一次，其他goroutine不会看到使用默认过期时间的中间状态
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) error {
+	kl, err := c.lockWrite(key, value, 0, false)
+	if err != nil {
+		return err
+	}
+	if kl != nil {
+		defer kl.Unlock()
+	}
 	defer c.mu.Unlock()
 
-	// 计算新的过期时间
 	var expireAt time.Time
-	if c.ttl > 0 {
-		expireAt = time.Now().Add(c.ttl)
+	if ttl > 0 {
+		expireAt = c.now().Add(ttl)
+	}
+	c.setEntry(entry[K, V]{key: key, value: value, expireAt: expireAt})
+	if c.writeBehind {
+		c.markDirty(key)
 	}
+	return nil
+}
 
-	if e, ok := c.items[key]; ok {
-		// 更新项 - 延长过期时间(除非原项永不过期)
-		item := e.Value.(entry[K, V])
-		if !item.expireAt.IsZero() { // 仅当原项有过期时间时更新
-			e.Value = entry[K, V]{key, value, expireAt}
-		} else {
-			e.Value = entry[K, V]{key, value, time.Time{}} // 保持永不过期
-		}
-		c.list.MoveToFront(e)
+// set 内部添加或更新方法，使用默认过期时间
+// 调用前必须持有锁
+func (c *Cache[K, V]) set(key K, value V) {
+	c.setEntry(entry[K, V]{key: key, value: value, expireAt: c.defaultExpireAt(key)})
+}
+
+// setEntry 添加或更新缓存项，更新时保留脏标记和置顶状态
+// 调用前必须持有锁
+func (c *Cache[K, V]) setEntry(item entry[K, V]) {
+	if e, ok := c.items[item.key]; ok {
+		item.dirty = e.entry.dirty
+		item.pinned = item.pinned || e.entry.pinned
+		c.replace(e, item)
 	} else {
-		// 新增项 - 使用计算的过期时间
-		e := c.list.PushFront(entry[K, V]{key, value, expireAt})
-		c.items[key] = e
+		c.insert(item)
+	}
+
+	c.logOp(item.record(walSet))
+}
+
+// SetMissing 记录键不存在（负缓存），避免反复查询后端数据源
+// 参数 key: 缓存项的键
+// 参数 ttl: 负缓存项的生存时间，如果为0或负值则使用NegativeTTL设置的默认值，
+// 两者都未设置时永不过期
+// 返回值: 该缓存项的句柄，支持链式调用
+// 负缓存项占用容量并参与LRU淘汰，但Get会将其视为未命中，Keys和Range也会跳过它；
+// 缓存被置顶项占满时不会写入新键
+func (c *Cache[K, V]) SetMissing(key K, ttl time.Duration) EntryOption[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed && c.admit(key, false) == nil {
+		c.setMissing(key, ttl)
+	}
+	return EntryOption[K, V]{key: key, owner: c}
+}
+
+// setMissing 内部写入负缓存项的方法
+// 调用前必须持有锁
+func (c *Cache[K, V]) setMissing(key K, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.negativeTTL
+	}
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = c.now().Add(ttl)
+	}
 
-		if c.list.Len() > c.size {
-			c.removeOldest()
+	c.put(entry[K, V]{key: key, expireAt: expireAt, missing: true})
+}
+
+// put 用给定的项替换已有项或插入新项，并移到最近使用位置，替换时保留置顶状态
+// 负缓存项和不带值的错误项不写入预写日志，替换有效值时记录一次删除，避免恢复后旧值重新出现
+// 调用前必须持有锁
+func (c *Cache[K, V]) put(item entry[K, V]) {
+	if e, ok := c.items[item.key]; ok {
+		item.pinned = item.pinned || e.entry.pinned
+		if e.entry.hasValue() && !item.hasValue() {
+			c.logOp(walRecord[K, V]{Op: walDelete, Key: item.key})
 		}
+		// 被替换的脏项不再需要写入
+		if e.entry.dirty {
+			c.dirty--
+		}
+		c.replace(e, item)
+	} else {
+		c.insert(item)
 	}
+}
 
-	return &entryOption[K, V]{key: key, cache: c}
+// insert 将新项放到链表头部，超出容量或成本上限时淘汰其他项
+// 调用前必须持有锁，且键不存在于缓存中
+func (c *Cache[K, V]) insert(item entry[K, V]) {
+	c.supersede(item.key)
+	c.bump(item.key)
+	c.drainReads()
+	e := c.list.PushFrontNode(c.newNode(item))
+	c.items[item.key] = e
+	c.track(&e.entry)
+	c.evict()
+}
+
+// replace 用新项替换节点中的项并移到最近使用位置，超出成本上限时淘汰其他项
+// 调用前必须持有锁
+func (c *Cache[K, V]) replace(e *node[K, V], item entry[K, V]) {
+	c.bump(item.key)
+	c.untrack(&e.entry)
+	e.entry = item
+	e.writes++
+	c.track(&e.entry)
+	if e.meta != nil {
+		e.meta.updated = c.now().UnixNano()
+	}
+	c.touch(e)
+	c.evict()
 }
 
 // Expire 为单个缓存项设置过期时间
 // 参数 duration: 过期时间，如果为0或负值则表示永不过期
-// 返回值: 指向该缓存项的句柄，支持链式调用
-func (h *entryOption[K, V]) Expire(duration time.Duration) *entryOption[K, V] {
-	h.cache.mu.Lock()
-	defer h.cache.mu.Unlock()
+// 返回值: 该缓存项的句柄，支持链式调用；对零值句柄调用不做任何操作
+func (h EntryOption[K, V]) Expire(duration time.Duration) EntryOption[K, V] {
+	if h.owner == nil {
+		return h
+	}
+	h.owner.expire(h.key, duration)
+	return h
+}
+
+// expire 修改缓存项的过期时间
+func (c *Cache[K, V]) expire(key K, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	if e, ok := h.cache.items[h.key]; ok {
-		item := e.Value.(entry[K, V])
+	if e, ok := c.items[key]; ok {
 		expireAt := time.Time{}
 		if duration > 0 {
-			expireAt = time.Now().Add(duration)
+			expireAt = c.now().Add(duration)
 		}
-		e.Value = entry[K, V]{item.key, item.value, expireAt}
+		c.setExpireAt(e, expireAt)
 	}
-
-	return h
 }
 
 // Get 获取缓存项的值，如果不存在或已过期则返回零值和false
 // 参数 key: 要获取的缓存项键
 // 返回值: 缓存项的值和是否存在/有效的标志
-// 注意: 成功获取会将该项移到最近使用位置
+// 注意: 成功获取会将该项移到最近使用位置；命中时只持有读锁，位置调整记录在读缓冲区中，
+// 在下一次写操作、Keys或Range之前批量应用，高并发下缓冲区写满时个别访问可能被丢弃
+// 绑定了Store时，未命中会从Store加载，需要错误信息请使用TryGet
 func (c *Cache[K, V]) Get(key K) (V, bool) {
+	value, err := c.TryGet(key)
+	return value, err == nil
+}
+
+// Lookup 获取缓存项，并区分真正的未命中和负缓存命中
+// 参数 key: 要获取的缓存项键
+// 返回值: 缓存项的值（仅LookupHit时有效）和查找结果
+// 与Get相同，命中（包括负缓存命中）会将该项移到最近使用位置，但未命中时不会从Store加载
+func (c *Cache[K, V]) Lookup(key K) (V, LookupResult) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	return c.get(key, true)
+	value, result := c.lookup(key, true)
+	c.record(result)
+	return value, result
+}
+
+// Stats 返回缓存的命中统计信息
+// Get和Lookup会更新统计，Peek不会
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:         c.hits.Load(),
+		Misses:       c.misses.Load(),
+		NegativeHits: c.negativeHits.Load(),
+	}
+}
+
+// record 根据查找结果更新命中统计
+func (c *Cache[K, V]) record(result LookupResult) {
+	switch result {
+	case LookupHit:
+		c.hits.Add(1)
+	case LookupNegative:
+		c.negativeHits.Add(1)
+	default:
+		c.misses.Add(1)
+	}
 }
 
-// get 内部获取方法，控制是否更新位置
+// lookup 内部查找方法，返回值和查找结果
 // 参数 key: 要获取的缓存项键
 // 参数 updatePos: 是否更新项在链表中的位置（移到最前）
-// 返回值: 缓存项的值和是否存在/有效的标志
-func (c *Cache[K, V]) get(key K, updatePos bool) (V, bool) {
+// 已过期的项会被删除并返回LookupMiss
+// 调用前必须持有写锁
+func (c *Cache[K, V]) lookup(key K, updatePos bool) (V, LookupResult) {
+	var zero V
 	if e, ok := c.items[key]; ok {
-		item := e.Value.(entry[K, V])
+		item := &e.entry
 		// 检查是否过期
-		if item.expireAt.IsZero() || time.Now().Before(item.expireAt) {
+		if !item.expired(c.now()) {
 			if updatePos {
-				c.list.MoveToFront(e)
+				c.touch(e)
 			}
-			return item.value, true
+			switch {
+			case item.missing:
+				return zero, LookupNegative
+			case !item.hasValue():
+				return zero, LookupError
+			}
+			if updatePos {
+				e.access(c.now())
+			}
+			return item.value, LookupHit
+		}
+		// 已过期，不需要保留旧值或失败次数时删除
+		if !c.retain(item) {
+			c.removeElement(e)
 		}
-		// 已过期，删除
-		c.removeElement(e)
 	}
-	var zero V
-	return zero, false
+	return zero, LookupMiss
 }
 
 // Peek 获取值但不更新位置
 // 参数 key: 要获取的缓存项键
 // 返回值: 缓存项的值和是否存在/有效的标志
-// 与Get不同，不会影响项的LRU顺序
+// 与Get不同，不会影响项的LRU顺序，也不会删除已过期的项，
+// 过期项留给Get、Purge和容量淘汰等持有写锁的操作删除
 func (c *Cache[K, V]) Peek(key K) (V, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.get(key, false)
+	return c.peek(key)
+}
+
+// peek 只读的查找方法，不更新位置也不删除过期项
+// 返回值: 缓存项的值和是否存在/有效的标志，负缓存项视为不存在
+// 调用前必须持有读锁或写锁
+func (c *Cache[K, V]) peek(key K) (V, bool) {
+	e, ok := c.live(key, c.now())
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return e.entry.value, true
 }
 
 // Delete 删除缓存项
 // 参数 key: 要删除的缓存项键
 // 返回值: 是否找到并删除了该项
+// 绑定了Store时会同时从Store中删除，需要错误信息请使用TryDelete
 func (c *Cache[K, V]) Delete(key K) bool {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if e, ok := c.items[key]; ok {
-		c.removeElement(e)
-		return true
-	}
-	return false
+	deleted, _ := c.TryDelete(key)
+	return deleted
 }
 
 // Size 返回当前缓存中的项数
@@ -307,23 +731,22 @@ func (c *Cache[K, V]) SetCapacity(size int) {
 
 	c.size = size
 	// 如果当前大小超过新容量，移除多余项
-	for c.list.Len() > c.size {
-		c.removeOldest()
-	}
+	c.evict()
 }
 
 // Keys 返回所有未过期的键
-// 返回值: 包含所有未过期键的切片，按照最近使用顺序排列
+// 返回值: 包含所有未过期键的切片，按照最近使用顺序排列，不包含负缓存项
 func (c *Cache[K, V]) Keys() []K {
+	c.syncReads()
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	keys := make([]K, 0, c.list.Len())
-	now := time.Now()
+	now := c.now()
 
 	for e := c.list.Front(); e != nil; e = e.Next() {
-		item := e.Value.(entry[K, V])
-		if item.expireAt.IsZero() || now.Before(item.expireAt) {
+		item := &e.entry
+		if item.hasValue() && !item.expired(now) {
 			keys = append(keys, item.key)
 		}
 	}
@@ -331,17 +754,18 @@ func (c *Cache[K, V]) Keys() []K {
 	return keys
 }
 
-// Range 遍历所有未过期的缓存项
+// Range 遍历所有未过期的缓存项，负缓存项会被跳过
 // 参数 fn: 对每个有效缓存项调用的函数，返回false可停止遍历
 // 遍历过程是按照最近使用顺序进行的
 func (c *Cache[K, V]) Range(fn func(K, V) bool) {
+	c.syncReads()
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	now := time.Now()
+	now := c.now()
 	for e := c.list.Front(); e != nil; e = e.Next() {
-		item := e.Value.(entry[K, V])
-		if item.expireAt.IsZero() || now.Before(item.expireAt) {
+		item := &e.entry
+		if item.hasValue() && !item.expired(now) {
 			if !fn(item.key, item.value) {
 				break
 			}
@@ -350,30 +774,144 @@ func (c *Cache[K, V]) Range(fn func(K, V) bool) {
 }
 
 // Clear 清空缓存
-// 删除缓存中的所有项
+// 删除缓存中的所有项，延迟写入模式下会在返回前将脏项写入Store，写入时不持有缓存锁，
+// 写入错误在下一次Flush或Close时报告
 func (c *Cache[K, V]) Clear() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	for e := c.list.Front(); e != nil; e = e.Next() {
+		if e.entry.dirty {
+			c.queueFlush(e)
+		}
+	}
+	c.logOp(walRecord[K, V]{Op: walClear})
+	c.reset()
+	c.mu.Unlock()
+
+	c.flushMu.Lock()
+	err := c.flush()
+	c.mu.Lock()
+	c.flushErr = errors.Join(c.flushErr, err)
+	c.mu.Unlock()
+	c.flushMu.Unlock()
+}
 
+// reset 删除所有项，不写入脏项也不触发回调，等待写入的已删除脏项不受影响
+// 调用前必须持有锁
+func (c *Cache[K, V]) reset() {
+	c.reads.drain()
+	for key := range c.deferred {
+		c.supersede(key)
+	}
 	c.list.Init()
-	c.items = make(map[K]*list.Element)
+	c.items = make(map[K]*node[K, V])
+	c.dirty = 0
+	c.cost = 0
+	c.prioritized = 0
+	c.pinned = 0
+	c.tags = nil
+}
+
+// touch 将项移到最近使用位置
+// 会先应用读缓冲区中更早的访问记录，保证LRU顺序与访问顺序一致
+// 调用前必须持有写锁
+func (c *Cache[K, V]) touch(e *node[K, V]) {
+	c.drainReads()
+	c.list.MoveToFront(e)
 }
 
-// removeOldest 删除最久未使用的项
-// 内部方法，从链表尾部删除元素
+// drainReads 将读缓冲区中的访问记录按顺序应用到链表
+// 已被删除或替换的项会被跳过
+// 调用前必须持有写锁
+func (c *Cache[K, V]) drainReads() {
+	for _, ev := range c.reads.drain() {
+		if e, ok := c.items[ev.node.entry.key]; ok && e == ev.node {
+			c.list.MoveToFront(e)
+		}
+	}
+}
+
+// syncReads 在有尚未应用的访问记录时获取写锁应用它们
+// 用于Keys和Range等按LRU顺序读取的操作，调用前不能持有锁
+func (c *Cache[K, V]) syncReads() {
+	if c.reads.pending.Load() {
+		c.mu.Lock()
+		c.drainReads()
+		c.mu.Unlock()
+	}
+}
+
+// evict 淘汰缓存项，直到项数不超过容量且总成本不超过成本上限
+// 最近写入的项（链表头部）不会因成本超限被淘汰
+// 调用前必须持有锁
+func (c *Cache[K, V]) evict() {
+	for c.list.Len() > c.size || (c.maxCost > 0 && c.cost > c.maxCost && c.list.Len() > 1) {
+		if !c.evictOne() {
+			// 剩余的项都已置顶
+			return
+		}
+	}
+}
+
+// evictOne 淘汰一项
+// 内部方法，删除victim选出的项并记录到预写日志，对未过期的有效项调用淘汰回调
+// 返回值: 是否淘汰了一项，没有可淘汰的项时返回false
+// 调用前必须持有锁
+func (c *Cache[K, V]) evictOne() bool {
+	c.drainReads()
+	e := c.victim()
+	if e == nil {
+		return false
+	}
+	c.removeElement(e)
+	item := &e.entry
+	// 读取不写入日志，重放时的LRU顺序可能不同，记录淘汰保证恢复出相同的项
+	c.logOp(walRecord[K, V]{Op: walDelete, Key: item.key})
+	if (c.onEvict != nil || c.onDemote != nil) && item.hasValue() && !item.expired(c.now()) {
+		if e.refs > 0 {
+			e.deferred = true
+			if c.deferred == nil {
+				c.deferred = make(map[K]*node[K, V])
+			}
+			c.deferred[item.key] = e
+		} else {
+			c.evicted(e)
+		}
+	}
+	return true
+}
+
+// evicted 以被淘汰的项调用淘汰回调和降级回调
+// 该键在推迟回调期间被重新写入或删除时不再降级，避免旧值覆盖二级缓存中的新值或恢复已删除的键
 // 调用前必须持有锁
-func (c *Cache[K, V]) removeOldest() {
-	if e := c.list.Back(); e != nil {
-		c.removeElement(e)
+func (c *Cache[K, V]) evicted(e *node[K, V]) {
+	item := &e.entry
+	if c.onEvict != nil {
+		c.onEvict(item.key, item.value, item.expireAt)
+	}
+	if c.onDemote != nil && !e.superseded {
+		c.onDemote(item.key, item.value, item.expireAt)
+	}
+}
+
+// supersede 标记该键等待句柄释放的旧项已被新写入或删除取代
+// 调用前必须持有锁
+func (c *Cache[K, V]) supersede(key K) {
+	if e, ok := c.deferred[key]; ok {
+		e.superseded = true
+		delete(c.deferred, key)
 	}
 }
 
 // removeElement 从缓存中删除元素
-// 参数 e: 要删除的链表元素
-// 内部方法，从链表和映射中删除指定元素
+// 参数 e: 要删除的链表节点
+// 内部方法，从链表和映射中删除指定元素，脏项交给写入协程写入Store
 // 调用前必须持有锁
-func (c *Cache[K, V]) removeElement(e *list.Element) {
+func (c *Cache[K, V]) removeElement(e *node[K, V]) {
 	c.list.Remove(e)
-	item := e.Value.(entry[K, V])
+	item := &e.entry
 	delete(c.items, item.key)
+	c.untrack(item)
+	if item.dirty {
+		c.queueFlush(e)
+	}
 }
@@ -2,9 +2,15 @@ package lru
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -119,6 +125,11 @@ func TestChainCall(t *testing.T) {
 	} else {
 		t.Log("✅ 元素'b'正确过期")
 	}
+
+	// 零值句柄不属于任何缓存
+	var zero EntryOption[string, int]
+	zero.Expire(time.Minute).Expire(0)
+	t.Log("✅ 零值句柄可以安全调用Expire")
 }
 
 // 测试Get方法
@@ -225,6 +236,42 @@ func TestPeek(t *testing.T) {
 	}
 }
 
+// 测试Peek不修改缓存，可与其他读操作并发
+// 使用go test -race运行时，如果读锁路径删除了过期项会报告数据竞争
+func TestPeekReadOnly(t *testing.T) {
+	t.Log("🔍 测试: Peek在读锁下不删除过期项")
+	cache := New[int, int](100)
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i).Expire(time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				cache.Peek(i)
+				cache.Keys()
+				cache.Range(func(int, int) bool { return true })
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cache.Size() != 100 {
+		t.Errorf("❌ Peek不应删除过期项, 缓存大小%d", cache.Size())
+	} else {
+		t.Log("✅ 过期项仍在缓存中，等待写锁路径删除")
+	}
+	if n := cache.Purge(); n != 100 {
+		t.Errorf("❌ Purge应删除100个过期项, 实际%d", n)
+	} else {
+		t.Log("✅ Purge删除了全部过期项")
+	}
+}
+
 // 测试Delete方法
 func TestDelete(t *testing.T) {
 	t.Log("🔍 测试: Delete方法(删除元素)")
@@ -735,6 +782,28 @@ func BenchmarkSet(b *testing.B) {
 	}
 }
 
+// 基准测试 - 更新已有项并设置过期时间
+func BenchmarkSetUpdate(b *testing.B) {
+	const size = 1024
+	cache := New[int, int](size)
+	for i := 0; i < size; i++ {
+		cache.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Set(i&(size-1), i).Expire(time.Minute)
+	}
+}
+
+// 基准测试 - 一次加锁写入值和过期时间
+func BenchmarkSetWithTTL(b *testing.B) {
+	cache := New[int, int](b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.SetWithTTL(i, i, time.Minute)
+	}
+}
+
 // 基准测试 - Get操作（缓存命中）
 func BenchmarkGetHit(b *testing.B) {
 	cache := New[int, int](b.N)
@@ -756,6 +825,45 @@ func BenchmarkGetMiss(b *testing.B) {
 	}
 }
 
+// 基准测试 - 并发Get操作（缓存命中）
+// 使用 -cpu=1,2,4,8 运行可以观察命中路径随并发度的扩展情况
+func BenchmarkGetParallel(b *testing.B) {
+	const size = 1 << 16
+	cache := New[int, int](size)
+	for i := 0; i < size; i++ {
+		cache.Set(i, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.IntN(size)
+		for pb.Next() {
+			cache.Get(i & (size - 1))
+			i++
+		}
+	})
+}
+
+// 基准测试 - 并发Get和Set混合操作（约10%写入）
+func BenchmarkGetSetParallel(b *testing.B) {
+	const size = 1 << 16
+	cache := New[int, int](size)
+	for i := 0; i < size; i++ {
+		cache.Set(i, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.IntN(size)
+		for pb.Next() {
+			if i%10 == 0 {
+				cache.Set(i&(size-1), i)
+			} else {
+				cache.Get(i & (size - 1))
+			}
+			i++
+		}
+	})
+}
+
 // 演示完整用例的示例
 func Example() {
 	// 创建一个容量为3的LRU缓存
@@ -867,40 +975,86 @@ func printCacheStatus[K comparable, V any](t *testing.T, cache *Cache[K, V]) {
 func TestCleanerLoopRecover(t *testing.T) {
 	t.Log("🔍 测试: cleanerLoop中的panic恢复机制")
 
-	// 由于无法直接触发cleanerLoop中的panic，我们通过一个更复杂的方式来提高测试覆盖率
-
-	// 创建缓存并设置非常短的清理周期，以触发多次清理
-	cache := New[string, int](3).Cleaner(5 * time.Millisecond)
-
-	// 添加一些会立即过期的项目
-	cache.Set("a", 1).Expire(1 * time.Millisecond)
-	cache.Set("b", 2).Expire(1 * time.Millisecond)
+	// 通过会panic的时钟在Purge中触发panic
+	var broken atomic.Bool
+	var logs bytes.Buffer
+	cache := New[string, int](3).Logger(slog.New(slog.NewTextHandler(&logs, nil)))
+	cache.now = func() time.Time {
+		if broken.Load() {
+			panic("时钟故障")
+		}
+		return time.Now()
+	}
+	defer cache.Close()
 
-	// 等待清理器运行几次
-	time.Sleep(30 * time.Millisecond)
+	cache.Cleaner(time.Millisecond)
+	cache.Set("a", 1).Expire(time.Millisecond)
 
-	// 添加一些不会过期的项目
-	cache.Set("c", 3)
-	cache.Set("d", 4)
-
-	// 检查清理器是否正常工作
-	if cache.Size() < 2 {
-		t.Errorf("❌ 应该有2个项目，实际有%d个", cache.Size())
+	// 等待清理器正常运行
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.CleanerHealth().LastRun.IsZero() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if health := cache.CleanerHealth(); !health.Running || health.LastRun.IsZero() {
+		t.Fatalf("❌ 清理器应正常运行: %+v", health)
 	}
+	t.Log("✅ 清理器正常运行")
 
-	// 再次触发清理，多一些迭代可能会提高覆盖率
-	time.Sleep(20 * time.Millisecond)
+	// 触发panic，等待重启次数达到上限后停止
+	broken.Store(true)
+	for cache.CleanerHealth().Running && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	health := cache.CleanerHealth()
+	t.Logf("📊 清理器状态: %+v", health)
+	if health.Running || health.Restarts != maxCleanerRestarts || health.LastError == nil {
+		t.Errorf("❌ 重启%d次后应停止并记录错误: %+v", maxCleanerRestarts, health)
+	} else {
+		t.Logf("✅ 重启%d次后停止清理", health.Restarts)
+	}
 
-	// 手动清理一次
-	cleaned := cache.Purge()
-	t.Logf("手动清理了 %d 项", cleaned)
+	if !strings.Contains(logs.String(), "cleaner panicked") || !strings.Contains(logs.String(), "cleaner stopped") {
+		t.Errorf("❌ panic和停止应记录到日志:\n%s", logs.String())
+	} else {
+		t.Log("✅ panic和停止已记录到Logger")
+	}
+}
 
-	// 停止清理器
-	cache.Close()
+// 测试偶发的panic不会使清理器停止
+func TestCleanerRestartReset(t *testing.T) {
+	t.Log("🔍 测试: 两次panic之间完成清理时重新计算重启次数")
+	var broken atomic.Bool
+	cache := New[string, int](3).Logger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	cache.now = func() time.Time {
+		if broken.CompareAndSwap(true, false) {
+			panic("偶发时钟故障")
+		}
+		return time.Now()
+	}
+	defer cache.Close()
+	cache.Cleaner(time.Millisecond)
+
+	deadline := time.Now().Add(5 * time.Second)
+	panics := 2 * maxCleanerRestarts
+	for i := 0; i < panics; i++ {
+		// 等待一次完成的清理后再触发下一次panic
+		last := cache.CleanerHealth().LastRun
+		for cache.CleanerHealth().LastRun.Equal(last) && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		broken.Store(true)
+		for cache.CleanerHealth().Restarts <= i && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
 
-	// 由于实际无法在测试中触发panic并恢复而不改变源代码，
-	// 我们只能假定这个测试至少执行了cleanerLoop的大部分代码路径
-	t.Log("✅ cleanerLoop基本功能已测试")
+	health := cache.CleanerHealth()
+	t.Logf("📊 清理器状态: %+v", health)
+	if !health.Running || health.Restarts != panics {
+		t.Errorf("❌ 偶发%d次panic后清理器应仍在运行: %+v", panics, health)
+	} else {
+		t.Logf("✅ 偶发%d次panic后清理器仍在运行", panics)
+	}
 }
 
 // 测试SetCapacity方法的深度验证
@@ -962,39 +1116,187 @@ func TestSetCapacityDetailed(t *testing.T) {
 	printCacheStatus(t, cache)
 }
 
-// 测试finalizer行为
-func TestFinalizerBehavior(t *testing.T) {
-	t.Log("🔍 测试: Finalizer自动释放资源行为")
+// 测试未关闭的缓存被回收后清理协程退出
+func TestCleanerCollected(t *testing.T) {
+	t.Log("🔍 测试: 未调用Close的缓存被回收后清理协程退出")
+	before := runtime.NumGoroutine()
 
 	// 定义一个函数，用于创建临时缓存并启动清理器
 	createAndForgetCache := func() {
-		// 创建带有清理器的缓存
-		cache := New[string, int](10).Cleaner(10 * time.Millisecond)
-
-		// 添加一些数据
+		cache := New[string, int](10).Cleaner(time.Millisecond)
 		cache.Set("a", 1)
 		cache.Set("b", 2)
-
-		// 不调用Close，依赖finalizer释放资源
-		// 注意：正常使用中应该调用Close
+		// 不调用Close，依赖垃圾回收释放资源
 	}
 
-	// 执行多次创建然后丢弃缓存的操作
 	for i := 0; i < 10; i++ {
 		createAndForgetCache()
 	}
+	t.Logf("📌 创建后协程数: %d (创建前%d)", runtime.NumGoroutine(), before)
+
+	// 反复触发垃圾回收，等待cleanup关闭信号通道、清理协程退出
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("❌ 清理协程泄漏: 回收后协程数%d, 创建前%d", n, before)
+	} else {
+		t.Log("✅ 缓存被回收后清理协程全部退出")
+	}
+
+	t.Log("⚠️ 提示: 实际使用中应该显式调用Close方法，而不是依赖垃圾回收")
+}
 
-	// 强制进行垃圾回收
-	runtime.GC()
+// 测试负缓存
+func TestSetMissing(t *testing.T) {
+	t.Log("🔍 测试: SetMissing负缓存和Lookup")
+	cache := New[string, int](3).NegativeTTL(50 * time.Millisecond)
 
-	// 等待一段时间，让finalizer有机会运行
+	cache.Set("a", 1)
+	cache.SetMissing("b", 0)
+
+	if _, result := cache.Lookup("b"); result != LookupNegative {
+		t.Errorf("❌ 元素'b'应为负缓存命中, 实际 %v", result)
+	} else {
+		t.Log("✅ Lookup正确区分负缓存命中")
+	}
+
+	if _, result := cache.Lookup("c"); result != LookupMiss {
+		t.Errorf("❌ 元素'c'应为未命中, 实际 %v", result)
+	}
+
+	if v, result := cache.Lookup("a"); result != LookupHit || v != 1 {
+		t.Errorf("❌ 元素'a'应命中: %v, %v", v, result)
+	}
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("❌ Get不应将负缓存项视为命中")
+	}
+
+	// 负缓存项占用容量，但不出现在Keys中
+	if cache.Size() != 2 {
+		t.Errorf("❌ 缓存大小应为2, 实际%d", cache.Size())
+	}
+	if keys := cache.Keys(); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("❌ Keys不应包含负缓存项: %v", keys)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.NegativeHits != 2 {
+		t.Errorf("❌ 统计信息错误: %+v", stats)
+	} else {
+		t.Logf("✅ 统计信息正确: %+v", stats)
+	}
+
+	// 负缓存项使用NegativeTTL过期
 	time.Sleep(100 * time.Millisecond)
+	if _, result := cache.Lookup("b"); result != LookupMiss {
+		t.Errorf("❌ 负缓存项'b'应该已过期, 实际 %v", result)
+	} else {
+		t.Log("✅ 负缓存项按NegativeTTL过期")
+	}
 
-	// 注意：我们不能直接验证finalizer是否运行，因为这是由GC控制的
-	// 这个测试主要是确保当有finalizer时程序不会崩溃
+	// 写入真实值会覆盖负缓存项
+	cache.SetMissing("c", time.Minute)
+	cache.Set("c", 3)
+	if v, result := cache.Lookup("c"); result != LookupHit || v != 3 {
+		t.Errorf("❌ Set应覆盖负缓存项: %v, %v", v, result)
+	} else {
+		t.Log("✅ Set正确覆盖负缓存项")
+	}
+}
+
+// 测试关闭后的生命周期
+func TestClose(t *testing.T) {
+	t.Log("🔍 测试: 关闭后的缓存拒绝操作")
+	store := newMemStore[string, int]()
+	cache := New[string, int](3).Bind(store).WriteBehind(0, 0)
+	cache.Set("a", 1)
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("❌ 关闭失败: %v", err)
+	}
+	if v, ok := store.get("a"); !ok || v != 1 {
+		t.Error("❌ 关闭时应写入脏项")
+	} else {
+		t.Log("✅ 关闭时写入了脏项")
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Errorf("❌ 重复关闭应返回nil, 实际%v", err)
+	}
+
+	cache.Set("b", 2)
+	if _, ok := cache.Get("a"); ok || cache.Size() != 0 {
+		t.Error("❌ 关闭后缓存应为空")
+	}
+	if err := cache.TrySet("b", 2); !errors.Is(err, ErrClosed) {
+		t.Errorf("❌ TrySet应返回ErrClosed, 实际%v", err)
+	}
+	if _, err := cache.TryGet("a"); !errors.Is(err, ErrClosed) {
+		t.Errorf("❌ TryGet应返回ErrClosed, 实际%v", err)
+	}
+	if _, err := cache.TryDelete("a"); !errors.Is(err, ErrClosed) {
+		t.Errorf("❌ TryDelete应返回ErrClosed, 实际%v", err)
+	}
+	if _, err := cache.GetOrLoad("a", func(string) (int, error) { return 1, nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("❌ GetOrLoad应返回ErrClosed, 实际%v", err)
+	}
+	if err := cache.Flush(); !errors.Is(err, ErrClosed) {
+		t.Errorf("❌ Flush应返回ErrClosed, 实际%v", err)
+	}
+	t.Log("✅ 关闭后的操作返回ErrClosed")
+}
+
+// 测试一次加锁设置过期时间
+func TestSetWithTTL(t *testing.T) {
+	t.Log("🔍 测试: SetWithTTL一次写入值和过期时间")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cache := New[string, int](3).TTL(time.Hour)
+	cache.now = clock.Now
+
+	if err := cache.SetWithTTL("a", 1, time.Minute); err != nil {
+		t.Fatalf("❌ SetWithTTL失败: %v", err)
+	}
+	cache.SetWithTTL("b", 2, 0)
+	clock.Advance(2 * time.Minute)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("❌ 'a'应使用指定的过期时间而不是默认TTL")
+	}
+	if _, ok := cache.Get("b"); !ok {
+		t.Error("❌ ttl为0的'b'应永不过期")
+	} else {
+		t.Log("✅ 指定的过期时间覆盖了默认TTL")
+	}
 
-	t.Log("✅ Finalizer行为测试完成 - 缓存资源应该已被释放")
+	cache.Close()
+	if err := cache.SetWithTTL("c", 3, time.Minute); !errors.Is(err, ErrClosed) {
+		t.Errorf("❌ 关闭后应返回ErrClosed, 实际%v", err)
+	}
+}
 
-	// 提醒开发者正确使用方式
-	t.Log("⚠️ 提示: 实际使用中应该显式调用Close方法，而不是依赖finalizer")
+// 测试更新已有项时不分配内存
+func TestSetAllocs(t *testing.T) {
+	t.Log("🔍 测试: 更新已有项时Set和SetWithTTL不分配内存")
+	cache := New[int, int](10)
+	var iface Interface[int, int] = cache
+	cache.Set(1, 1)
+
+	cases := map[string]func(){
+		"Set":           func() { cache.Set(1, 2) },
+		"Set.Expire":    func() { cache.Set(1, 2).Expire(time.Minute) },
+		"SetWithTTL":    func() { cache.SetWithTTL(1, 2, time.Minute) },
+		"Interface.Set": func() { iface.Set(1, 2).Expire(time.Minute) },
+	}
+	for name, fn := range cases {
+		if n := testing.AllocsPerRun(100, fn); n != 0 {
+			t.Errorf("❌ %s: 每次分配%.0f次", name, n)
+		} else {
+			t.Logf("✅ %s: 没有内存分配", name)
+		}
+	}
 }
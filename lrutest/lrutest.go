@@ -0,0 +1,261 @@
+// Package lrutest 提供缓存实现的一致性测试套件
+//
+// 任何满足lru.Interface的LRU缓存实现（包括对lru.Cache的封装）都可以通过
+// Run验证容量淘汰顺序、过期时间、Peek不更新位置、Expire链式调用、
+// SetCapacity缩容以及并发安全等行为是否符合lru.Cache的约定。
+package lrutest
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JieBaiYou/lru"
+)
+
+// Factory 创建指定容量的空缓存
+type Factory func(capacity int) lru.Interface[string, int]
+
+// Run 对factory创建的缓存实现运行完整的一致性测试套件
+// 每个子测试都会创建新的缓存实例，并在结束时调用Close
+func Run(t *testing.T, factory Factory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(*testing.T, Factory)
+	}{
+		{"SetGet", testSetGet},
+		{"EvictionOrder", testEvictionOrder},
+		{"TTLExpiration", testTTLExpiration},
+		{"PeekDoesNotPromote", testPeekDoesNotPromote},
+		{"ExpireChain", testExpireChain},
+		{"SetCapacityShrink", testSetCapacityShrink},
+		{"DeleteClear", testDeleteClear},
+		{"Concurrency", testConcurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, factory)
+		})
+	}
+}
+
+// newCache 创建缓存并在测试结束时关闭
+func newCache(t *testing.T, factory Factory, capacity int) lru.Interface[string, int] {
+	t.Helper()
+	cache := factory(capacity)
+	t.Cleanup(func() {
+		if err := cache.Close(); err != nil {
+			t.Errorf("❌ Close返回错误: %v", err)
+		}
+	})
+	return cache
+}
+
+// expectKeys 检查Keys是否按最近使用顺序返回期望的键
+func expectKeys(t *testing.T, cache lru.Interface[string, int], want ...string) {
+	t.Helper()
+	if got := cache.Keys(); !slices.Equal(got, want) {
+		t.Errorf("❌ Keys顺序错误: 期望 %v, 实际 %v", want, got)
+	}
+}
+
+// 测试基本读写和更新
+func testSetGet(t *testing.T, factory Factory) {
+	cache := newCache(t, factory, 3)
+
+	cache.Set("a", 1)
+	cache.Set("a", 10)
+	if v, ok := cache.Get("a"); !ok || v != 10 {
+		t.Errorf("❌ Get('a')应返回更新后的值10, 实际 %v, %v", v, ok)
+	}
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("❌ 不存在的键不应命中")
+	}
+	if cache.Size() != 1 {
+		t.Errorf("❌ 更新已有键不应增加大小, 实际%d", cache.Size())
+	}
+	if cache.Capacity() != 3 {
+		t.Errorf("❌ 容量应为3, 实际%d", cache.Capacity())
+	}
+}
+
+// 测试容量淘汰顺序
+func testEvictionOrder(t *testing.T, factory Factory) {
+	cache := newCache(t, factory, 3)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.Get("a") // "b"成为最久未使用的项
+	cache.Set("d", 4)
+
+	if _, ok := cache.Peek("b"); ok {
+		t.Error("❌ 最久未使用的'b'应被淘汰")
+	}
+	expectKeys(t, cache, "d", "a", "c")
+
+	cache.Set("c", 30) // 更新会将"c"移到最前
+	cache.Set("e", 5)
+	expectKeys(t, cache, "e", "c", "d")
+}
+
+// 测试过期时间
+func testTTLExpiration(t *testing.T, factory Factory) {
+	cache := newCache(t, factory, 3)
+
+	cache.Set("a", 1).Expire(20 * time.Millisecond)
+	cache.Set("b", 2)
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok := cache.Peek("a"); ok {
+		t.Error("❌ Peek不应返回已过期的'a'")
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("❌ Get不应返回已过期的'a'")
+	}
+	expectKeys(t, cache, "b")
+
+	cache.Set("c", 3).Expire(10 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	cache.Purge()
+	if cache.Size() != 1 {
+		t.Errorf("❌ Purge后应只剩1项, 实际%d项", cache.Size())
+	}
+	if v, ok := cache.Get("b"); !ok || v != 2 {
+		t.Errorf("❌ 未设置过期时间的'b'不应过期: %v, %v", v, ok)
+	}
+}
+
+// 测试Peek不更新位置
+func testPeekDoesNotPromote(t *testing.T, factory Factory) {
+	cache := newCache(t, factory, 3)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	if v, ok := cache.Peek("a"); !ok || v != 1 {
+		t.Fatalf("❌ Peek('a')失败: %v, %v", v, ok)
+	}
+	expectKeys(t, cache, "c", "b", "a")
+
+	cache.Set("d", 4)
+	if _, ok := cache.Peek("a"); ok {
+		t.Error("❌ Peek不应更新位置，'a'应被淘汰")
+	}
+}
+
+// 测试Expire链式调用
+func testExpireChain(t *testing.T, factory Factory) {
+	cache := newCache(t, factory, 3)
+
+	cache.Set("a", 1).Expire(10 * time.Millisecond).Expire(0) // 最后一次设置生效
+	cache.Set("b", 2).Expire(time.Hour).Expire(10 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("❌ Expire(0)应使'a'永不过期")
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Error("❌ 最后一次Expire应生效，'b'应已过期")
+	}
+
+	// 句柄对已删除的键不产生任何效果
+	h := cache.Set("c", 3)
+	cache.Delete("c")
+	h.Expire(time.Hour)
+	if _, ok := cache.Peek("c"); ok {
+		t.Error("❌ 对已删除键的Expire不应恢复该键")
+	}
+}
+
+// 测试SetCapacity缩容
+func testSetCapacityShrink(t *testing.T, factory Factory) {
+	cache := newCache(t, factory, 5)
+
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		cache.Set(k, i)
+	}
+	cache.Get("a")
+	cache.SetCapacity(2)
+
+	if cache.Capacity() != 2 {
+		t.Errorf("❌ 容量应为2, 实际%d", cache.Capacity())
+	}
+	if cache.Size() != 2 {
+		t.Errorf("❌ 缩容后大小应为2, 实际%d", cache.Size())
+	}
+	expectKeys(t, cache, "a", "e")
+
+	cache.SetCapacity(4)
+	cache.Set("f", 5)
+	cache.Set("g", 6)
+	expectKeys(t, cache, "g", "f", "a", "e")
+}
+
+// 测试删除和清空
+func testDeleteClear(t *testing.T, factory Factory) {
+	cache := newCache(t, factory, 3)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if !cache.Delete("a") {
+		t.Error("❌ Delete('a')应返回true")
+	}
+	if cache.Delete("a") {
+		t.Error("❌ 重复Delete('a')应返回false")
+	}
+
+	cache.Clear()
+	if cache.Size() != 0 || len(cache.Keys()) != 0 {
+		t.Errorf("❌ Clear后应为空, 实际大小%d", cache.Size())
+	}
+
+	n := 0
+	cache.Range(func(string, int) bool {
+		n++
+		return true
+	})
+	if n != 0 {
+		t.Errorf("❌ Clear后Range不应遍历任何项, 实际%d项", n)
+	}
+}
+
+// 测试并发安全
+func testConcurrency(t *testing.T, factory Factory) {
+	const capacity = 64
+	cache := newCache(t, factory, capacity)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("k%d", (g*31+i)%128)
+				switch i % 5 {
+				case 0, 1:
+					cache.Set(key, i)
+				case 2:
+					cache.Get(key)
+				case 3:
+					cache.Peek(key)
+				case 4:
+					cache.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if size := cache.Size(); size > capacity {
+		t.Errorf("❌ 并发操作后大小%d超出容量%d", size, capacity)
+	}
+	if keys := cache.Keys(); len(keys) > capacity {
+		t.Errorf("❌ 并发操作后键数量%d超出容量%d", len(keys), capacity)
+	}
+}
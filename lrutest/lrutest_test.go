@@ -0,0 +1,22 @@
+package lrutest
+
+import (
+	"testing"
+
+	"github.com/JieBaiYou/lru"
+)
+
+// 测试lru.Cache满足一致性约定
+func TestCache(t *testing.T) {
+	Run(t, func(capacity int) lru.Interface[string, int] {
+		return lru.New[string, int](capacity)
+	})
+}
+
+// 测试两级缓存满足一致性约定
+func TestTieredCache(t *testing.T) {
+	Run(t, func(capacity int) lru.Interface[string, int] {
+		l2 := lru.New[string, int](capacity)
+		return lru.NewTiered(lru.New[string, int](capacity), lru.AsTier(l2))
+	})
+}
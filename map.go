@@ -0,0 +1,163 @@
+package lru
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Map 是基于map的无容量限制缓存实现
+// 支持单个缓存项的过期时间，但不淘汰任何项，Keys和Range的顺序不确定
+type Map[K comparable, V any] struct {
+	mu    sync.RWMutex
+	items map[K]mapEntry[V] // 存储键到缓存项的映射
+}
+
+// mapEntry 表示Map中的条目
+type mapEntry[V any] struct {
+	value    V         // 缓存项的值
+	expireAt time.Time // 缓存项的过期时间点，零值表示永不过期
+}
+
+// alive 报告该项在now时是否未过期
+func (e mapEntry[V]) alive(now time.Time) bool {
+	return e.expireAt.IsZero() || now.Before(e.expireAt)
+}
+
+// NewMap 创建无容量限制的缓存
+func NewMap[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{items: make(map[K]mapEntry[V])}
+}
+
+// Set 添加或更新缓存项，更新时会清除原有的过期时间
+// 返回值: 指向该缓存项的句柄，可用于进一步设置过期时间
+func (m *Map[K, V]) Set(key K, value V) EntryOption[K, V] {
+	m.mu.Lock()
+	m.items[key] = mapEntry[V]{value: value}
+	m.mu.Unlock()
+	return EntryOption[K, V]{key: key, owner: m}
+}
+
+// Get 获取缓存项的值，如果不存在或已过期则返回零值和false
+// 已过期的项会被删除
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var zero V
+	item, ok := m.items[key]
+	if !ok {
+		return zero, false
+	}
+	if !item.alive(time.Now()) {
+		delete(m.items, key)
+		return zero, false
+	}
+	return item.value, true
+}
+
+// Peek 获取缓存项的值，Map没有使用顺序，因此与Get相同但不删除过期项
+func (m *Map[K, V]) Peek(key K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var zero V
+	item, ok := m.items[key]
+	if !ok || !item.alive(time.Now()) {
+		return zero, false
+	}
+	return item.value, true
+}
+
+// Delete 删除缓存项
+// 返回值: 是否找到并删除了该项
+func (m *Map[K, V]) Delete(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.items[key]
+	delete(m.items, key)
+	return ok
+}
+
+// Size 返回当前缓存中的项数，可能包含尚未清理的过期项
+func (m *Map[K, V]) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items)
+}
+
+// Capacity 返回math.MaxInt，表示没有容量限制
+func (m *Map[K, V]) Capacity() int { return math.MaxInt }
+
+// SetCapacity 不做任何操作，Map没有容量限制
+func (m *Map[K, V]) SetCapacity(size int) {}
+
+// Keys 返回所有未过期的键，顺序不确定
+func (m *Map[K, V]) Keys() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]K, 0, len(m.items))
+	now := time.Now()
+	for key, item := range m.items {
+		if item.alive(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Range 遍历所有未过期的缓存项，顺序不确定
+// 参数 fn: 对每个有效缓存项调用的函数，返回false可停止遍历
+func (m *Map[K, V]) Range(fn func(K, V) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for key, item := range m.items {
+		if item.alive(now) && !fn(key, item.value) {
+			break
+		}
+	}
+}
+
+// Clear 清空缓存
+func (m *Map[K, V]) Clear() {
+	m.mu.Lock()
+	m.items = make(map[K]mapEntry[V])
+	m.mu.Unlock()
+}
+
+// Purge 清理所有过期项，返回清理的项数
+func (m *Map[K, V]) Purge() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	now := time.Now()
+	for key, item := range m.items {
+		if !item.alive(now) {
+			delete(m.items, key)
+			count++
+		}
+	}
+	return count
+}
+
+// Close 总是返回nil，Map不占用额外资源
+func (m *Map[K, V]) Close() error { return nil }
+
+// expire 修改缓存项的过期时间
+func (m *Map[K, V]) expire(key K, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if item, ok := m.items[key]; ok {
+		item.expireAt = time.Time{}
+		if duration > 0 {
+			item.expireAt = time.Now().Add(duration)
+		}
+		m.items[key] = item
+	}
+}
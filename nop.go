@@ -0,0 +1,59 @@
+package lru
+
+import "time"
+
+// Nop 是不保存任何数据的缓存实现
+// 所有写入都会被丢弃，所有读取都未命中，适用于禁用缓存或测试
+type Nop[K comparable, V any] struct{}
+
+// NewNop 创建不保存任何数据的缓存
+func NewNop[K comparable, V any]() *Nop[K, V] {
+	return &Nop[K, V]{}
+}
+
+// Set 丢弃写入，返回的句柄上的操作也不会生效
+func (n *Nop[K, V]) Set(key K, value V) EntryOption[K, V] {
+	return EntryOption[K, V]{key: key, owner: n}
+}
+
+// Get 总是返回零值和false
+func (n *Nop[K, V]) Get(key K) (V, bool) {
+	var zero V
+	return zero, false
+}
+
+// Peek 总是返回零值和false
+func (n *Nop[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	return zero, false
+}
+
+// Delete 总是返回false
+func (n *Nop[K, V]) Delete(key K) bool { return false }
+
+// Size 总是返回0
+func (n *Nop[K, V]) Size() int { return 0 }
+
+// Capacity 总是返回0
+func (n *Nop[K, V]) Capacity() int { return 0 }
+
+// SetCapacity 不做任何操作
+func (n *Nop[K, V]) SetCapacity(size int) {}
+
+// Keys 总是返回空切片
+func (n *Nop[K, V]) Keys() []K { return []K{} }
+
+// Range 不会调用fn
+func (n *Nop[K, V]) Range(fn func(K, V) bool) {}
+
+// Clear 不做任何操作
+func (n *Nop[K, V]) Clear() {}
+
+// Purge 总是返回0
+func (n *Nop[K, V]) Purge() int { return 0 }
+
+// Close 总是返回nil
+func (n *Nop[K, V]) Close() error { return nil }
+
+// expire 不做任何操作
+func (n *Nop[K, V]) expire(key K, duration time.Duration) {}
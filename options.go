@@ -0,0 +1,292 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Option 是NewWithOptions的配置选项
+type Option func(*config) error
+
+// config 收集NewWithOptions的配置，与键值类型相关的选项以any保存，创建缓存时再检查类型
+type config struct {
+	size            int              // 缓存的最大容量
+	ttl             time.Duration    // 缓存项的默认过期时间
+	cleanerInterval time.Duration    // 自动清理的时间间隔，为0时不启动清理协程
+	negativeTTL     time.Duration    // 负缓存项的默认过期时间
+	errorTTL        time.Duration    // 加载错误的初始缓存时间
+	errorMaxTTL     time.Duration    // 加载错误缓存时间的退避上限
+	serveStale      bool             // 加载失败时是否返回旧值
+	writeBehind     bool             // 是否启用延迟写入
+	flushSize       int              // 延迟写入的批量大小
+	flushInterval   time.Duration    // 延迟写入的时间间隔
+	onEvict         any              // func(K, V, time.Time)
+	store           any              // Store[K, V]
+	now             func() time.Time // 获取当前时间
+	logger          *slog.Logger     // 记录后台协程异常的日志记录器
+	maxCost         int64            // 成本上限
+	untracked       bool             // 是否关闭缓存项的元数据记录
+	updatePolicy    UpdatePolicy     // 更新已有项时过期时间的计算方式
+	maxPinned       int              // 置顶项数量上限
+	walPath         string           // 预写日志路径，为空时不启用
+	walSync         SyncPolicy       // 预写日志的fsync策略
+}
+
+// WithCapacity 设置缓存的最大容量，必须大于0
+// 未设置时使用默认容量DefaultCacheSize
+func WithCapacity(size int) Option {
+	return func(cfg *config) error {
+		if size <= 0 {
+			return fmt.Errorf("lru: capacity must be positive, got %d", size)
+		}
+		cfg.size = size
+		return nil
+	}
+}
+
+// WithTTL 设置缓存项的默认过期时间，必须大于0
+func WithTTL(duration time.Duration) Option {
+	return func(cfg *config) error {
+		if duration <= 0 {
+			return fmt.Errorf("lru: ttl must be positive, got %v", duration)
+		}
+		cfg.ttl = duration
+		return nil
+	}
+}
+
+// WithCleaner 设置自动清理过期项的时间间隔，必须大于0
+// 注意: 设置后不再使用缓存时应调用Close方法停止清理goroutine
+func WithCleaner(interval time.Duration) Option {
+	return func(cfg *config) error {
+		if interval <= 0 {
+			return fmt.Errorf("lru: cleaner interval must be positive, got %v", interval)
+		}
+		cfg.cleanerInterval = interval
+		return nil
+	}
+}
+
+// WithNegativeTTL 设置负缓存项的默认过期时间，必须大于0
+func WithNegativeTTL(duration time.Duration) Option {
+	return func(cfg *config) error {
+		if duration <= 0 {
+			return fmt.Errorf("lru: negative ttl must be positive, got %v", duration)
+		}
+		cfg.negativeTTL = duration
+		return nil
+	}
+}
+
+// WithErrorTTL 设置加载错误的缓存时间，参数含义与ErrorTTL相同
+// base必须大于0，max为0时不退避，否则不能小于base
+func WithErrorTTL(base, max time.Duration) Option {
+	return func(cfg *config) error {
+		if base <= 0 {
+			return fmt.Errorf("lru: error ttl must be positive, got %v", base)
+		}
+		if max != 0 && max < base {
+			return fmt.Errorf("lru: max error ttl %v is less than base %v", max, base)
+		}
+		cfg.errorTTL = base
+		cfg.errorMaxTTL = max
+		return nil
+	}
+}
+
+// WithServeStale 设置加载失败时继续返回上一次成功加载的值，需要同时设置WithErrorTTL
+func WithServeStale() Option {
+	return func(cfg *config) error {
+		cfg.serveStale = true
+		return nil
+	}
+}
+
+// WithWriteBehind 启用延迟写入模式，参数含义与WriteBehind相同，需要同时设置WithStore
+// batchSize和interval不能都小于等于0，否则脏项只会在淘汰和Close时写入
+func WithWriteBehind(batchSize int, interval time.Duration) Option {
+	return func(cfg *config) error {
+		if batchSize <= 0 && interval <= 0 {
+			return errors.New("lru: write-behind needs a positive batch size or interval")
+		}
+		cfg.writeBehind = true
+		cfg.flushSize = batchSize
+		cfg.flushInterval = interval
+		return nil
+	}
+}
+
+// WithOnEvict 设置容量淘汰时的回调函数，参数含义与OnEvict相同
+// 键值类型必须与NewWithOptions的类型参数一致
+func WithOnEvict[K comparable, V any](fn func(key K, value V, expireAt time.Time)) Option {
+	return func(cfg *config) error {
+		if fn == nil {
+			return errors.New("lru: evict hook is nil")
+		}
+		cfg.onEvict = fn
+		return nil
+	}
+}
+
+// WithStore 将缓存绑定到后端存储，参数含义与Bind相同
+// 键值类型必须与NewWithOptions的类型参数一致
+func WithStore[K comparable, V any](store Store[K, V]) Option {
+	return func(cfg *config) error {
+		if store == nil {
+			return errors.New("lru: store is nil")
+		}
+		cfg.store = store
+		return nil
+	}
+}
+
+// WithClock 设置获取当前时间的函数，默认为time.Now，可在测试中替换为假时钟
+// 注意: 清理和延迟写入协程的触发间隔仍使用真实时间
+func WithClock(now func() time.Time) Option {
+	return func(cfg *config) error {
+		if now == nil {
+			return errors.New("lru: clock is nil")
+		}
+		cfg.now = now
+		return nil
+	}
+}
+
+// WithMaxCost 设置所有缓存项的成本上限，参数含义与MaxCost相同，必须大于0
+func WithMaxCost(max int64) Option {
+	return func(cfg *config) error {
+		if max <= 0 {
+			return fmt.Errorf("lru: max cost must be positive, got %d", max)
+		}
+		cfg.maxCost = max
+		return nil
+	}
+}
+
+// WithLogger 设置记录后台协程异常的日志记录器，参数含义与Logger相同
+func WithLogger(l *slog.Logger) Option {
+	return func(cfg *config) error {
+		if l == nil {
+			return errors.New("lru: logger is nil")
+		}
+		cfg.logger = l
+		return nil
+	}
+}
+
+// WithEntryTracking 设置是否记录缓存项的元数据，参数含义与TrackEntries相同
+func WithEntryTracking(enable bool) Option {
+	return func(cfg *config) error {
+		cfg.untracked = !enable
+		return nil
+	}
+}
+
+// WithUpdatePolicy 设置更新已有项时过期时间的计算方式，参数含义与UpdatePolicy相同
+func WithUpdatePolicy(p UpdatePolicy) Option {
+	return func(cfg *config) error {
+		if !p.valid() {
+			return fmt.Errorf("lru: unknown update policy %d", p)
+		}
+		cfg.updatePolicy = p
+		return nil
+	}
+}
+
+// WithMaxPinned 设置置顶项的数量上限，参数含义与MaxPinned相同，必须大于0
+func WithMaxPinned(n int) Option {
+	return func(cfg *config) error {
+		if n <= 0 {
+			return fmt.Errorf("lru: max pinned must be positive, got %d", n)
+		}
+		cfg.maxPinned = n
+		return nil
+	}
+}
+
+// WithWAL 在创建缓存时打开预写日志并恢复数据，参数含义与OpenWAL相同，path不能为空
+func WithWAL(path string, sync SyncPolicy) Option {
+	return func(cfg *config) error {
+		if path == "" {
+			return errors.New("lru: wal path is empty")
+		}
+		if sync < 0 {
+			return fmt.Errorf("lru: invalid wal sync policy %d", sync)
+		}
+		cfg.walPath = path
+		cfg.walSync = sync
+		return nil
+	}
+}
+
+// NewWithOptions 使用配置选项创建缓存
+// 参数 opts: 配置选项，后出现的选项覆盖先出现的同类选项
+// 返回值: 创建的缓存，以及无效参数或无效组合的错误，设置了WithWAL时还包括打开或恢复预写日志的错误
+// 与New不同，无效的参数不会被替换为默认值，而是返回错误；
+// 所有配置在缓存返回前完成，不会与并发使用产生竞争
+func NewWithOptions[K comparable, V any](opts ...Option) (*Cache[K, V], error) {
+	cfg := config{size: DefaultCacheSize, now: time.Now}
+	var errs []error
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var onEvict func(K, V, time.Time)
+	if cfg.onEvict != nil {
+		fn, ok := cfg.onEvict.(func(K, V, time.Time))
+		if !ok {
+			errs = append(errs, fmt.Errorf("lru: evict hook type %T does not match cache", cfg.onEvict))
+		}
+		onEvict = fn
+	}
+	var store Store[K, V]
+	if cfg.store != nil {
+		s, ok := cfg.store.(Store[K, V])
+		if !ok {
+			errs = append(errs, fmt.Errorf("lru: store type %T does not match cache", cfg.store))
+		}
+		store = s
+	}
+	if cfg.serveStale && cfg.errorTTL == 0 {
+		errs = append(errs, errors.New("lru: serve stale requires error ttl"))
+	}
+	if cfg.writeBehind && cfg.store == nil {
+		errs = append(errs, errors.New("lru: write-behind requires a store"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	c := New[K, V](cfg.size)
+	c.ttl = cfg.ttl
+	c.negativeTTL = cfg.negativeTTL
+	c.errorTTL = cfg.errorTTL
+	c.errorMaxTTL = cfg.errorMaxTTL
+	c.serveStale = cfg.serveStale
+	c.onEvict = onEvict
+	c.store = store
+	c.now = cfg.now
+	c.logger = cfg.logger
+	c.maxCost = cfg.maxCost
+	c.untracked = cfg.untracked
+	c.updatePolicy = cfg.updatePolicy
+	c.maxPinned = cfg.maxPinned
+
+	// 在启动后台协程之前恢复，出错时无需停止它们
+	if cfg.walPath != "" {
+		if err := c.OpenWAL(cfg.walPath, cfg.walSync); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.cleanerInterval > 0 {
+		c.Cleaner(cfg.cleanerInterval)
+	}
+	if cfg.writeBehind {
+		c.WriteBehind(cfg.flushSize, cfg.flushInterval)
+	}
+	return c, nil
+}
@@ -0,0 +1,69 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+// 测试使用配置选项创建缓存
+func TestNewWithOptions(t *testing.T) {
+	t.Log("🔍 测试: 使用配置选项创建缓存")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	var evicted []string
+
+	cache, err := NewWithOptions[string, int](
+		WithCapacity(2),
+		WithTTL(time.Minute),
+		WithClock(clock.Now),
+		WithOnEvict(func(key string, value int, expireAt time.Time) {
+			evicted = append(evicted, key)
+		}),
+		WithStore[string, int](newMemStore[string, int]()),
+	)
+	if err != nil {
+		t.Fatalf("❌ 创建缓存失败: %v", err)
+	}
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	if cache.Capacity() != 2 || len(evicted) != 1 || evicted[0] != "a" {
+		t.Errorf("❌ 容量或淘汰回调不正确: 容量%d, 淘汰%v", cache.Capacity(), evicted)
+	} else {
+		t.Log("✅ 容量和淘汰回调生效")
+	}
+
+	clock.Advance(time.Minute)
+	if _, ok := cache.Peek("b"); ok {
+		t.Error("❌ 按假时钟推进后'b'应已过期")
+	} else {
+		t.Log("✅ TTL和时钟选项生效")
+	}
+}
+
+// 测试无效的选项和组合
+func TestNewWithOptionsInvalid(t *testing.T) {
+	t.Log("🔍 测试: 无效的配置选项返回错误")
+	cases := map[string][]Option{
+		"容量为0":            {WithCapacity(0)},
+		"负TTL":            {WithTTL(-time.Second)},
+		"清理间隔为0":          {WithCleaner(0)},
+		"错误退避上限小于初始值":     {WithErrorTTL(time.Minute, time.Second)},
+		"ServeStale无错误缓存": {WithServeStale()},
+		"延迟写入无Store":      {WithWriteBehind(10, 0)},
+		"时钟为nil":          {WithClock(nil)},
+		"回调类型不匹配":         {WithOnEvict(func(int, int, time.Time) {})},
+		"Store类型不匹配":      {WithStore[int, int](newMemStore[int, int]())},
+		"预写日志路径为空":        {WithWAL("", SyncNever)},
+	}
+
+	for name, opts := range cases {
+		cache, err := NewWithOptions[string, int](opts...)
+		if err == nil || cache != nil {
+			t.Errorf("❌ %s: 应返回错误", name)
+		} else {
+			t.Logf("✅ %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package lru
+
+import "errors"
+
+// ErrFull 表示置顶项已达到数量上限，或缓存已被置顶项占满无法写入新键
+var ErrFull = errors.New("lru: cache is full of pinned entries")
+
+// Pin 置顶缓存项，置顶的项不会因容量或成本上限被淘汰
+// 参数 key: 缓存项的键
+// 返回值: 键不存在（包括已过期、负缓存和错误项）时返回ErrNotFound，
+// 置顶项数量达到MaxPinned设置的上限或缓存容量时返回ErrFull
+// 置顶不改变过期时间，置顶项仍会过期，也可以被Delete、InvalidateTag和Clear删除；
+// 需要永不过期时可同时调用Persist
+func (c *Cache[K, V]) Pin(key K) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.live(key, c.now())
+	switch {
+	case !ok:
+		return ErrNotFound
+	case e.entry.pinned:
+		return nil
+	case c.pinned >= c.pinLimit():
+		return ErrFull
+	}
+	c.setPinned(e, true)
+	return nil
+}
+
+// Unpin 取消置顶，该项重新参与淘汰
+// 参数 key: 缓存项的键
+// 返回值: 该项是否存在且处于置顶状态
+// 容量或成本上限在置顶期间被调低时，取消置顶后会立即淘汰超出的项
+func (c *Cache[K, V]) Unpin(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok || !e.entry.pinned {
+		return false
+	}
+	c.setPinned(e, false)
+	c.evict()
+	return true
+}
+
+// MaxPinned 设置置顶项的数量上限
+// 参数 n: 置顶项数量上限，小于等于0时以缓存容量为上限
+// 返回缓存实例本身，支持链式调用
+// 调低上限不会取消已有的置顶，只会使之后的Pin返回ErrFull
+func (c *Cache[K, V]) MaxPinned(n int) *Cache[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n < 0 {
+		n = 0
+	}
+	c.maxPinned = n
+	return c
+}
+
+// pinLimit 返回置顶项的数量上限
+// 调用前必须持有锁
+func (c *Cache[K, V]) pinLimit() int {
+	if c.maxPinned > 0 && c.maxPinned < c.size {
+		return c.maxPinned
+	}
+	return c.size
+}
+
+// admit 检查能否写入key
+// 参数 pin: 写入后是否置顶该项
+// 返回值: 缓存已被置顶项占满且key不存在，或需要置顶但已达到上限时返回ErrFull
+// 调用前必须持有锁
+func (c *Cache[K, V]) admit(key K, pin bool) error {
+	e, ok := c.items[key]
+	if pin && (!ok || !e.entry.pinned) && c.pinned >= c.pinLimit() {
+		return ErrFull
+	}
+	if !ok && c.pinned >= c.size {
+		return ErrFull
+	}
+	return nil
+}
+
+// setPinned 修改缓存项的置顶状态并记录到预写日志
+// 调用前必须持有锁
+func (c *Cache[K, V]) setPinned(e *node[K, V], pinned bool) {
+	c.untrack(&e.entry)
+	e.entry.pinned = pinned
+	c.track(&e.entry)
+	c.logOp(walRecord[K, V]{Op: walPin, Key: e.entry.key, Pinned: pinned})
+}
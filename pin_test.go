@@ -0,0 +1,182 @@
+package lru
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// 测试置顶项不会被容量淘汰
+func TestPin(t *testing.T) {
+	t.Log("🔍 测试: 置顶项跳过容量淘汰")
+	cache := New[string, int](3)
+	cache.Set("config", 1)
+	if err := cache.Pin("config"); err != nil {
+		t.Fatalf("❌ Pin失败: %v", err)
+	}
+	for i, key := range []string{"a", "b", "c", "d"} {
+		cache.Set(key, i)
+	}
+	if keys := cache.Keys(); !slices.Equal(keys, []string{"d", "c", "config"}) {
+		t.Errorf("❌ 剩余的键应为[d c config], 实际%v", keys)
+	} else {
+		t.Log("✅ 最久未使用的置顶项被跳过")
+	}
+
+	if err := cache.Pin("none"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("❌ 置顶不存在的键应返回ErrNotFound, 实际%v", err)
+	}
+	if err := cache.Pin("config"); err != nil {
+		t.Errorf("❌ 重复置顶应成功, 实际%v", err)
+	}
+
+	cache.Set("config", 2)
+	cache.Set("e", 5)
+	cache.Set("f", 6)
+	if _, ok := cache.Peek("config"); !ok {
+		t.Error("❌ 更新值后应保持置顶")
+	}
+
+	if !cache.Unpin("config") || cache.Unpin("config") {
+		t.Error("❌ Unpin应只在项处于置顶状态时返回true")
+	}
+	cache.Set("g", 7)
+	cache.Set("h", 8)
+	if _, ok := cache.Peek("config"); ok {
+		t.Error("❌ 取消置顶后应重新参与淘汰")
+	} else {
+		t.Log("✅ 取消置顶后被正常淘汰")
+	}
+
+	cache.Set("x", 1)
+	cache.Pin("x")
+	if !cache.Delete("x") {
+		t.Error("❌ 置顶项应可以被Delete删除")
+	}
+	if err := cache.Validate(); err != nil {
+		t.Errorf("❌ Validate() = %v", err)
+	}
+}
+
+// 测试置顶项数量上限和缓存被置顶项占满
+func TestPinLimit(t *testing.T) {
+	t.Log("🔍 测试: 置顶上限和ErrFull")
+	cache := New[string, int](3).MaxPinned(2)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, 1)
+	}
+	cache.Pin("a")
+	cache.Pin("b")
+	if err := cache.Pin("c"); !errors.Is(err, ErrFull) {
+		t.Errorf("❌ 达到置顶上限应返回ErrFull, 实际%v", err)
+	} else {
+		t.Logf("✅ 达到上限: %v", err)
+	}
+	if err := cache.SetWithOptions("d", 1, EntryOptions{Pinned: true}); !errors.Is(err, ErrFull) {
+		t.Errorf("❌ SetWithOptions置顶超过上限应返回ErrFull, 实际%v", err)
+	}
+	if _, ok := cache.Peek("d"); ok {
+		t.Error("❌ 返回错误时不应写入缓存")
+	}
+
+	cache.MaxPinned(0)
+	if err := cache.SetWithOptions("c", 2, EntryOptions{Pinned: true}); err != nil {
+		t.Fatalf("❌ 以容量为上限时应可以置顶, 实际%v", err)
+	}
+	if err := cache.TrySet("d", 1); !errors.Is(err, ErrFull) {
+		t.Errorf("❌ 缓存被置顶项占满时TrySet应返回ErrFull, 实际%v", err)
+	} else {
+		t.Logf("✅ 缓存被占满: %v", err)
+	}
+	if err := cache.TrySet("a", 2); err != nil {
+		t.Errorf("❌ 更新已有的置顶项应成功, 实际%v", err)
+	}
+
+	if err := cache.SetWithTTL("d", 1, time.Minute); !errors.Is(err, ErrFull) {
+		t.Errorf("❌ 缓存被置顶项占满时SetWithTTL应返回ErrFull, 实际%v", err)
+	}
+	cache.Set("d", 1)
+	if _, ok := cache.Peek("d"); ok || cache.Size() != 3 {
+		t.Errorf("❌ 缓存被置顶项占满时Set不应写入新键, 项数%d", cache.Size())
+	} else {
+		t.Log("✅ Set没有写入新键")
+	}
+
+	// 负缓存和加载结果等内部写入无法返回错误，不写入新键也不触发淘汰回调
+	var evicted []string
+	cache.OnEvict(func(key string, _ int, _ time.Time) {
+		evicted = append(evicted, key)
+	})
+	cache.SetMissing("m", 0)
+	if _, result := cache.Lookup("m"); result != LookupMiss || cache.Size() != 3 {
+		t.Errorf("❌ 不应写入负缓存项, 项数%d", cache.Size())
+	}
+	if v, err := cache.GetOrLoad("x", func(string) (int, error) { return 7, nil }); err != nil || v != 7 {
+		t.Errorf("❌ GetOrLoad应返回加载结果: %v, %v", v, err)
+	}
+	if _, ok := cache.Peek("x"); ok || len(evicted) != 0 {
+		t.Errorf("❌ 加载结果不应写入缓存或触发淘汰回调, 淘汰%v", evicted)
+	} else {
+		t.Log("✅ 加载结果没有写入缓存")
+	}
+	cache.OnEvict(nil)
+
+	cache.SetCapacity(2)
+	if cache.Size() != 3 {
+		t.Errorf("❌ 调低容量不应淘汰置顶项, 项数%d", cache.Size())
+	}
+	if err := cache.Validate(); err != nil {
+		t.Errorf("❌ Validate() = %v", err)
+	}
+	cache.Unpin("a")
+	if _, ok := cache.Peek("a"); ok || cache.Size() != 2 {
+		t.Error("❌ 取消置顶后应立即淘汰超出容量的项")
+	}
+}
+
+// 测试置顶项仍会过期，置顶状态可以从预写日志恢复
+func TestPinExpireAndWAL(t *testing.T) {
+	t.Log("🔍 测试: 置顶项的过期和恢复")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := New[string, int](2).TTL(time.Minute)
+	cache.now = clock.Now
+	if err := cache.OpenWAL(path, SyncNever); err != nil {
+		t.Fatal(err)
+	}
+	cache.SetWithOptions("a", 1, EntryOptions{Pinned: true, NoExpire: true})
+	cache.Set("b", 2)
+	cache.Pin("b")
+	cache.Set("c", 3)
+	cache.Pin("c")
+	cache.Unpin("c")
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered := New[string, int](2)
+	recovered.now = clock.Now
+	if err := recovered.OpenWAL(path, SyncNever); err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.Close()
+	if keys := recovered.Keys(); !slices.Equal(keys, []string{"b", "a"}) {
+		t.Errorf("❌ 恢复后的键应为[b a], 实际%v", keys)
+	} else {
+		t.Log("✅ 置顶状态从预写日志恢复")
+	}
+
+	clock.Advance(2 * time.Minute)
+	if _, ok := recovered.Get("b"); ok {
+		t.Error("❌ 置顶项应按TTL过期")
+	}
+	if _, ok := recovered.Get("a"); !ok {
+		t.Error("❌ 永不过期的置顶项应保留")
+	}
+	if err := recovered.Validate(); err != nil {
+		t.Errorf("❌ Validate() = %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package lru
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync/atomic"
+)
+
+// readStripeSize 是每个条带能缓冲的访问记录数
+const readStripeSize = 16
+
+// readEvent 是一次被缓冲的访问记录
+type readEvent[T any] struct {
+	seq  uint64 // 访问序号，用于在应用时恢复访问顺序
+	node *T     // 被访问的节点
+}
+
+// readStripe 是读缓冲区的一个条带
+type readStripe[T any] struct {
+	head   atomic.Uint32                // 下一个写入位置，超过readStripeSize时记录被丢弃
+	events [readStripeSize]readEvent[T] // 缓冲的访问记录
+	_      [64]byte                     // 避免相邻条带的伪共享
+}
+
+// readBuffer 是条带化的有损访问记录缓冲区
+// 读操作在持有读锁时通过push记录访问，持有写锁时通过drain批量取出；
+// 同一时刻只有读者写入或写者读取，读锁和写锁保证了两者之间的可见性
+type readBuffer[T any] struct {
+	seq     atomic.Uint64   // 全局访问序号
+	pending atomic.Bool     // 是否有尚未取出的记录
+	stripes []readStripe[T] // 条带，数量为2的幂
+	mask    uint32          // 条带数量减一，用于选择条带
+	scratch []readEvent[T]  // drain时复用的缓冲区
+}
+
+// newReadBuffer 创建读缓冲区，条带数量为不小于GOMAXPROCS的2的幂
+func newReadBuffer[T any]() *readBuffer[T] {
+	n := 1
+	for n < runtime.GOMAXPROCS(0) {
+		n <<= 1
+	}
+	return &readBuffer[T]{
+		stripes: make([]readStripe[T], n),
+		mask:    uint32(n - 1),
+	}
+}
+
+// push 记录一次访问
+// 返回值: 是否写满了条带，写满时调用者应尽快在写锁下调用drain
+// 条带已满时记录会被直接丢弃
+// 调用前必须持有读锁
+func (b *readBuffer[T]) push(node *T) bool {
+	s := &b.stripes[rand.Uint32()&b.mask]
+	i := s.head.Add(1) - 1
+	if i >= readStripeSize {
+		return true
+	}
+	s.events[i] = readEvent[T]{seq: b.seq.Add(1), node: node}
+	if !b.pending.Load() {
+		b.pending.Store(true)
+	}
+	return i == readStripeSize-1
+}
+
+// drain 取出所有缓冲的访问记录，按访问顺序返回
+// 返回值: 访问记录，在下一次调用drain之前有效
+// 调用前必须持有写锁
+func (b *readBuffer[T]) drain() []readEvent[T] {
+	if !b.pending.Load() {
+		return nil
+	}
+
+	clear(b.scratch)
+	events := b.scratch[:0]
+	for i := range b.stripes {
+		s := &b.stripes[i]
+		n := min(s.head.Load(), readStripeSize)
+		events = append(events, s.events[:n]...)
+		clear(s.events[:n])
+		s.head.Store(0)
+	}
+	slices.SortFunc(events, func(x, y readEvent[T]) int { return cmp.Compare(x.seq, y.seq) })
+
+	b.scratch = events
+	b.pending.Store(false)
+	return events
+}
@@ -0,0 +1,92 @@
+package lru
+
+import (
+	"sync"
+	"testing"
+)
+
+// 测试读缓冲区按访问顺序取出记录
+func TestReadBufferOrder(t *testing.T) {
+	t.Log("🔍 测试: 读缓冲区按访问顺序取出记录")
+	buf := newReadBuffer[int]()
+	nodes := make([]int, readStripeSize)
+	for i := range nodes {
+		buf.push(&nodes[i])
+	}
+
+	events := buf.drain()
+	if len(events) != len(nodes) {
+		t.Fatalf("❌ 应取出%d条记录, 实际%d条", len(nodes), len(events))
+	}
+	for i, ev := range events {
+		if ev.node != &nodes[i] {
+			t.Fatalf("❌ 第%d条记录顺序错误", i)
+		}
+	}
+	if buf.pending.Load() || len(buf.drain()) != 0 {
+		t.Error("❌ 取出后缓冲区应为空")
+	} else {
+		t.Log("✅ 记录按访问顺序取出，取出后缓冲区为空")
+	}
+}
+
+// 测试读锁命中的访问在淘汰和遍历前生效
+func TestBufferedGetOrder(t *testing.T) {
+	t.Log("🔍 测试: 缓冲的Get访问保持LRU顺序")
+	cache := New[int, int](100)
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i)
+	}
+	// 按从旧到新的顺序访问，访问后0应为最近使用的项
+	for i := 99; i >= 0; i-- {
+		cache.Get(i)
+	}
+	if keys := cache.Keys(); keys[0] != 0 || keys[99] != 99 {
+		t.Errorf("❌ Keys应反映Get顺序, 实际首项%d末项%d", keys[0], keys[99])
+	} else {
+		t.Log("✅ Keys反映了缓冲的访问顺序")
+	}
+
+	cache.Get(99)
+	cache.Set(100, 100)
+	if _, ok := cache.Peek(99); !ok {
+		t.Error("❌ 刚访问的99不应被淘汰")
+	}
+	if _, ok := cache.Peek(98); ok {
+		t.Error("❌ 最久未使用的98应被淘汰")
+	} else {
+		t.Log("✅ 淘汰前应用了缓冲的访问")
+	}
+}
+
+// 测试并发Get与写操作交错时内部结构保持一致
+func TestBufferedGetConcurrent(t *testing.T) {
+	t.Log("🔍 测试: 并发Get、Set和Delete后内部结构一致")
+	cache := New[int, int](64)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := (i * (g + 1)) % 128
+				switch i % 5 {
+				case 0:
+					cache.Set(key, i)
+				case 1:
+					cache.Delete(key)
+				default:
+					cache.Get(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if err := cache.Validate(); err != nil {
+		t.Errorf("❌ 内部结构不一致: %v", err)
+	} else {
+		t.Log("✅ 并发操作后内部结构一致")
+	}
+}
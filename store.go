@@ -0,0 +1,291 @@
+package lru
+
+import (
+	"fmt"
+	"hash/maphash"
+	"sync"
+)
+
+// keyLockStripes 是串行化Store写入的键锁数量
+const keyLockStripes = 64
+
+// keySeed 是计算键锁下标的哈希种子
+var keySeed = maphash.MakeSeed()
+
+// Store 是缓存可以绑定的后端存储
+// 绑定后，Get未命中时从Store加载，Set先同步写入Store再更新缓存，Delete同时从两者删除
+type Store[K comparable, V any] interface {
+	// Load 加载单个键，键不存在时应返回ErrNotFound
+	Load(key K) (V, error)
+	// LoadMany 批量加载多个键，不存在的键不出现在返回结果中
+	LoadMany(keys []K) (map[K]V, error)
+	// Store 写入单个键值对
+	Store(key K, value V) error
+	// Delete 删除单个键，键不存在时应返回nil
+	Delete(key K) error
+}
+
+// Bind 将缓存绑定到后端存储
+// 参数 store: 后端存储，为nil时解除绑定
+// 返回缓存实例本身，支持链式调用
+func (c *Cache[K, V]) Bind(store Store[K, V]) *Cache[K, V] {
+	c.mu.Lock()
+	c.store = store
+	c.mu.Unlock()
+	return c
+}
+
+// TryGet 获取缓存项，未命中时从绑定的Store加载
+// 参数 key: 要获取的缓存项键
+// 返回值: 缓存项的值和错误，键不存在时返回ErrNotFound
+// 加载结果的缓存规则与GetOrLoad相同
+func (c *Cache[K, V]) TryGet(key K) (V, error) {
+	value, err, miss, ok := c.cached(key)
+	if ok {
+		return value, err
+	}
+
+	c.mu.RLock()
+	store := c.store
+	c.mu.RUnlock()
+
+	if store == nil {
+		c.cancel(key, miss)
+		return value, ErrNotFound
+	}
+	return c.load(key, func(key K) (V, error) {
+		return c.loadStore(store, key)
+	}, miss)
+}
+
+// loadStore 从Store加载key，该键的脏项已被删除但尚未写入Store时返回该脏项的值
+// 调用前不能持有锁
+func (c *Cache[K, V]) loadStore(store Store[K, V], key K) (V, error) {
+	c.mu.RLock()
+	e, ok := c.pending[key]
+	c.mu.RUnlock()
+
+	if ok {
+		return e.entry.value, nil
+	}
+	return store.Load(key)
+}
+
+// TryGetMany 批量获取缓存项，未命中的键通过Store.LoadMany一次加载
+// 参数 keys: 要获取的缓存项键
+// 返回值: 找到的键值对和加载错误，不存在的键不出现在结果中
+// Store中不存在的键会被记录为负缓存项；加载期间被写入或删除的键只返回加载结果，不写入缓存，
+// 缓存被置顶项占满时新键也不写入缓存
+func (c *Cache[K, V]) TryGetMany(keys []K) (map[K]V, error) {
+	values := make(map[K]V, len(keys))
+	var (
+		missed []K
+		gens   []uint64
+	)
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	for _, key := range keys {
+		value, result := c.lookup(key, true)
+		c.record(result)
+		switch result {
+		case LookupHit:
+			values[key] = value
+		case LookupMiss:
+			// 尚未写入Store的脏项比Store中的值更新
+			if value, ok := c.revive(key); ok {
+				values[key] = value
+				continue
+			}
+			missed = append(missed, key)
+			gens = append(gens, c.startLoad(key))
+		}
+	}
+	store := c.store
+	c.mu.Unlock()
+
+	if len(missed) == 0 {
+		return values, nil
+	}
+	if store == nil {
+		c.mu.Lock()
+		for i, key := range missed {
+			c.endLoad(key, gens[i])
+		}
+		c.mu.Unlock()
+		return values, nil
+	}
+
+	loaded, err := store.LoadMany(missed)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 先结束所有加载并判断哪些键在加载期间被写入，写入加载结果本身也会计为一次写入
+	stale := make([]bool, len(missed))
+	for i, key := range missed {
+		stale[i] = !c.endLoad(key, gens[i]) || c.closed
+	}
+	if err != nil {
+		return values, err
+	}
+	for i, key := range missed {
+		value, ok := loaded[key]
+		if ok {
+			values[key] = value
+		}
+		switch {
+		case stale[i] || c.admit(key, false) != nil:
+		case ok:
+			c.set(key, value)
+		default:
+			c.setMissing(key, 0)
+		}
+	}
+	return values, nil
+}
+
+// TrySet 添加或更新缓存项，绑定了Store时先同步写入Store
+// 参数 key: 缓存项的键
+// 参数 value: 缓存项的值
+// 返回值: 缓存已被置顶项占满时返回ErrFull，以及Store写入错误，出错时缓存保持不变；
+// 写入Store期间缓存被关闭或被置顶项占满时，Store已经更新，缓存会删除该键的旧项后返回错误
+// 启用了WriteBehind时不会同步写入，而是将该项标记为脏项
+func (c *Cache[K, V]) TrySet(key K, value V) error {
+	kl, err := c.lockWrite(key, value, 0, false)
+	if err != nil {
+		return err
+	}
+	if kl != nil {
+		defer kl.Unlock()
+	}
+	defer c.mu.Unlock()
+
+	c.set(key, value)
+	if c.writeBehind {
+		c.markDirty(key)
+	}
+	return nil
+}
+
+// lockWrite 获取写入key所需的锁，未启用延迟写入时先将value同步写入Store
+// 参数 cost、pin: 写入项的成本和是否置顶，用于检查成本上限和置顶项
+// 返回值: 需要在释放缓存锁之后释放的键锁（不需要时为nil）和错误；
+// 返回nil错误时已持有缓存锁，出错时不持有任何锁
+// 写入Store期间不持有缓存锁，读写其他键不会被Store的延迟阻塞；
+// 同一个键的写入由键锁串行化，保证Store和缓存中同一个键的写入顺序一致
+func (c *Cache[K, V]) lockWrite(key K, value V, cost int64, pin bool) (*sync.Mutex, error) {
+	c.mu.Lock()
+	if err := c.writeCheck(key, cost, pin); err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	store := c.store
+	if store == nil || c.writeBehind {
+		return nil, nil
+	}
+	c.mu.Unlock()
+
+	kl := c.keyLock(key)
+	kl.Lock()
+	if err := store.Store(key, value); err != nil {
+		kl.Unlock()
+		return nil, err
+	}
+	c.mu.Lock()
+	// 写入Store期间缓存可能已关闭或被置顶项占满，此时Store已经更新，
+	// 删除该键的旧项，避免缓存继续返回与Store不一致的值
+	if err := c.writeCheck(key, cost, pin); err != nil {
+		if e, ok := c.items[key]; ok && !c.closed {
+			c.clean(key)
+			c.removeElement(e)
+			c.logOp(walRecord[K, V]{Op: walDelete, Key: key})
+		}
+		c.mu.Unlock()
+		kl.Unlock()
+		return nil, err
+	}
+	return kl, nil
+}
+
+// writeCheck 检查能否写入key
+// 返回值: 缓存已关闭、成本超过上限或置顶项占满缓存时的错误
+// 调用前必须持有锁
+func (c *Cache[K, V]) writeCheck(key K, cost int64, pin bool) error {
+	if c.closed {
+		return ErrClosed
+	}
+	if c.maxCost > 0 && cost > c.maxCost {
+		return fmt.Errorf("lru: cost %d exceeds max cost %d", cost, c.maxCost)
+	}
+	return c.admit(key, pin)
+}
+
+// keyLock 返回串行化key的Store写入的键锁，不同的键可能共用同一个锁
+// 获取键锁时不能持有缓存锁
+func (c *Cache[K, V]) keyLock(key K) *sync.Mutex {
+	return &c.keyLocks[stripe(key)]
+}
+
+// stripe 返回key对应的键锁的下标
+func stripe[K comparable](key K) uint64 {
+	return maphash.Comparable(keySeed, key) % keyLockStripes
+}
+
+// bump 记录key的一次写入或删除，使该键正在进行的加载结果不再写入缓存
+// 调用前必须持有锁
+func (c *Cache[K, V]) bump(key K) {
+	if f, ok := c.loading[key]; ok {
+		f.writes++
+	}
+}
+
+// TryDelete 删除缓存项，绑定了Store时同时从Store删除
+// 参数 key: 要删除的缓存项键
+// 返回值: 缓存中是否找到并删除了该项，以及Store删除错误，出错时缓存保持不变
+func (c *Cache[K, V]) TryDelete(key K) (bool, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return false, ErrClosed
+	}
+	if store := c.store; store != nil {
+		c.mu.Unlock()
+
+		// 与lockWrite相同，在不持有缓存锁时删除，由键锁保证同一个键的顺序；
+		// 持有flushMu直到清除脏标记，避免写入协程在删除Store之后把旧值写回
+		kl := c.keyLock(key)
+		kl.Lock()
+		defer kl.Unlock()
+		c.flushMu.Lock()
+		if err := store.Delete(key); err != nil {
+			c.flushMu.Unlock()
+			return false, err
+		}
+		c.mu.Lock()
+		c.clean(key)
+		delete(c.pending, key)
+		c.flushMu.Unlock()
+		if c.closed {
+			c.mu.Unlock()
+			return false, ErrClosed
+		}
+	}
+	defer c.mu.Unlock()
+
+	// 该键不在缓存中时也可能有正在进行的加载，加载结果已从Store删除，不应再写入缓存；
+	// 同样总是记录删除，重放时该键可能因淘汰顺序不同仍在缓存中
+	c.bump(key)
+	c.supersede(key)
+	c.logOp(walRecord[K, V]{Op: walDelete, Key: key})
+	if e, ok := c.items[key]; ok {
+		// 已从Store删除，脏项无需再写入
+		c.clean(key)
+		c.removeElement(e)
+		return true, nil
+	}
+	return false, nil
+}
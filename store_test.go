@@ -0,0 +1,326 @@
+package lru
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// memStore 是用于测试的内存Store实现
+type memStore[K comparable, V any] struct {
+	mu       sync.Mutex
+	data     map[K]V
+	loads    int         // Load和LoadMany的调用次数
+	failNext error       // 下一次写操作返回的错误
+	failKeys map[K]error // 写入这些键时返回对应的错误
+}
+
+func newMemStore[K comparable, V any]() *memStore[K, V] {
+	return &memStore[K, V]{data: make(map[K]V)}
+}
+
+func (s *memStore[K, V]) Load(key K) (V, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	if v, ok := s.data[key]; ok {
+		return v, nil
+	}
+	var zero V
+	return zero, ErrNotFound
+}
+
+func (s *memStore[K, V]) LoadMany(keys []K) (map[K]V, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	values := make(map[K]V)
+	for _, k := range keys {
+		if v, ok := s.data[k]; ok {
+			values[k] = v
+		}
+	}
+	return values, nil
+}
+
+func (s *memStore[K, V]) Store(key K, value V) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.failNext; err != nil {
+		s.failNext = nil
+		return err
+	}
+	if err := s.failKeys[key]; err != nil {
+		return err
+	}
+	s.data[key] = value
+	return nil
+}
+
+func (s *memStore[K, V]) Delete(key K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.failNext; err != nil {
+		s.failNext = nil
+		return err
+	}
+	delete(s.data, key)
+	return nil
+}
+
+// get 读取Store中的值
+func (s *memStore[K, V]) get(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	return v, ok
+}
+
+// 测试读穿透
+func TestStoreReadThrough(t *testing.T) {
+	t.Log("🔍 测试: 绑定Store后的读穿透")
+	store := newMemStore[string, int]()
+	store.data["a"] = 1
+	cache := New[string, int](3).Bind(store)
+
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Fatalf("❌ 未命中时应从Store加载: %v, %v", v, ok)
+	}
+	cache.Get("a")
+	if store.loads != 1 {
+		t.Errorf("❌ 加载后应命中缓存, Store加载了%d次", store.loads)
+	} else {
+		t.Log("✅ 未命中时从Store加载并缓存")
+	}
+
+	if _, err := cache.TryGet("x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("❌ Store中不存在的键应返回ErrNotFound, 实际 %v", err)
+	}
+
+	store.data["b"] = 2
+	values, err := cache.TryGetMany([]string{"a", "b", "y"})
+	if err != nil || len(values) != 2 || values["a"] != 1 || values["b"] != 2 {
+		t.Errorf("❌ 批量获取结果错误: %v, %v", values, err)
+	} else {
+		t.Log("✅ TryGetMany批量加载成功")
+	}
+	if _, result := cache.Lookup("y"); result != LookupNegative {
+		t.Errorf("❌ Store中不存在的键应记录为负缓存项, 实际 %v", result)
+	}
+}
+
+// 测试写穿透和删除
+func TestStoreWriteThrough(t *testing.T) {
+	t.Log("🔍 测试: 绑定Store后的写穿透和删除")
+	store := newMemStore[string, int]()
+	cache := New[string, int](3).Bind(store)
+
+	cache.Set("a", 1)
+	if v, ok := store.get("a"); !ok || v != 1 {
+		t.Fatalf("❌ Set应同步写入Store: %v, %v", v, ok)
+	}
+
+	errWrite := errors.New("write failed")
+	store.failNext = errWrite
+	if err := cache.TrySet("a", 2); !errors.Is(err, errWrite) {
+		t.Errorf("❌ TrySet应返回Store错误, 实际 %v", err)
+	}
+	if v, _ := cache.Peek("a"); v != 1 {
+		t.Errorf("❌ Store写入失败时缓存不应更新, 实际 %v", v)
+	} else {
+		t.Log("✅ Store写入失败时缓存保持不变")
+	}
+
+	store.failNext = errWrite
+	if deleted, err := cache.TryDelete("a"); deleted || !errors.Is(err, errWrite) {
+		t.Errorf("❌ TryDelete应返回Store错误: %v, %v", deleted, err)
+	}
+
+	if !cache.Delete("a") {
+		t.Error("❌ Delete应删除缓存项")
+	}
+	if _, ok := store.get("a"); ok {
+		t.Error("❌ Delete应同时从Store删除")
+	} else {
+		t.Log("✅ Delete同时从缓存和Store删除")
+	}
+}
+
+// blockingStore 是写入键"a"时阻塞到release关闭为止的测试Store
+type blockingStore struct {
+	*memStore[string, int]
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Store(key string, value int) error {
+	if key == "a" {
+		close(s.started)
+		<-s.release
+	}
+	return s.memStore.Store(key, value)
+}
+
+// 测试写入Store期间不阻塞其他键的读写
+func TestStoreWriteDoesNotBlockCache(t *testing.T) {
+	t.Log("🔍 测试: 同步写入Store期间其他键可以正常读写")
+	store := &blockingStore{
+		memStore: newMemStore[string, int](),
+		started:  make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+	cache := New[string, int](3).Bind(store)
+	cache.Set("b", 2)
+
+	done := make(chan error)
+	go func() { done <- cache.TrySet("a", 1) }()
+	<-store.started
+
+	if v, ok := cache.Peek("b"); !ok || v != 2 {
+		t.Errorf("❌ 写入Store期间应能读取其他键: %v, %v", v, ok)
+	}
+	// 选一个与"a"不共用键锁的键，共用键锁的键会等待"a"写入完成
+	other := "c"
+	for stripe(other) == stripe("a") {
+		other += "c"
+	}
+	if err := cache.TrySet(other, 3); err != nil {
+		t.Errorf("❌ 写入Store期间应能写入其他键: %v", err)
+	}
+	if _, ok := cache.Peek("a"); ok {
+		t.Error("❌ Store写入完成前缓存中不应出现该键")
+	}
+
+	close(store.release)
+	if err := <-done; err != nil {
+		t.Fatalf("❌ TrySet失败: %v", err)
+	}
+	if v, ok := cache.Peek("a"); !ok || v != 1 {
+		t.Errorf("❌ Store写入完成后缓存应更新: %v, %v", v, ok)
+	} else {
+		t.Log("✅ 写入Store时不持有缓存锁")
+	}
+}
+
+// hookStore 是在操作完成后调用一次钩子的测试Store，用于在缓存不持有锁的窗口中插入其他操作
+type hookStore struct {
+	*memStore[string, int]
+	onLoad   func() // Load或LoadMany读取完成后调用
+	onStore  func() // Store写入完成后调用
+	onDelete func() // Delete删除完成后调用
+}
+
+// fire 调用并清除钩子
+func (s *hookStore) fire(hook *func()) {
+	if fn := *hook; fn != nil {
+		*hook = nil
+		fn()
+	}
+}
+
+func (s *hookStore) Load(key string) (int, error) {
+	v, err := s.memStore.Load(key)
+	s.fire(&s.onLoad)
+	return v, err
+}
+
+func (s *hookStore) LoadMany(keys []string) (map[string]int, error) {
+	values, err := s.memStore.LoadMany(keys)
+	s.fire(&s.onLoad)
+	return values, err
+}
+
+func (s *hookStore) Store(key string, value int) error {
+	err := s.memStore.Store(key, value)
+	s.fire(&s.onStore)
+	return err
+}
+
+func (s *hookStore) Delete(key string) error {
+	err := s.memStore.Delete(key)
+	s.fire(&s.onDelete)
+	return err
+}
+
+// 测试加载期间被删除或更新的键不会写入旧值
+func TestStoreLoadRace(t *testing.T) {
+	t.Log("🔍 测试: 加载期间的删除和写入优先于加载结果")
+	store := &hookStore{memStore: newMemStore[string, int]()}
+	cache := New[string, int](3).Bind(store)
+
+	store.data["k"] = 1
+	store.onLoad = func() { cache.Delete("k") }
+	if v, ok := cache.Get("k"); !ok || v != 1 {
+		t.Errorf("❌ Get应返回加载到的值: %v, %v", v, ok)
+	}
+	if v, ok := cache.Peek("k"); ok {
+		t.Errorf("❌ 加载期间被删除的键不应写入缓存, 实际 %v", v)
+	} else {
+		t.Log("✅ 加载期间被删除的键没有被写回缓存")
+	}
+
+	store.data["k"] = 1
+	store.onLoad = func() { cache.Set("k", 2) }
+	cache.Get("k")
+	if v, _ := cache.Peek("k"); v != 2 {
+		t.Errorf("❌ 加载结果不应覆盖加载期间写入的新值, 实际 %v", v)
+	}
+
+	store.data["m"] = 1
+	store.onLoad = func() { cache.Delete("m") }
+	if values, err := cache.TryGetMany([]string{"m"}); err != nil || values["m"] != 1 {
+		t.Errorf("❌ TryGetMany应返回加载到的值: %v, %v", values, err)
+	}
+	if _, result := cache.Lookup("m"); result != LookupMiss {
+		t.Errorf("❌ 批量加载期间被删除的键不应写入缓存, 实际 %v", result)
+	} else {
+		t.Log("✅ 批量加载期间被删除的键没有被写回缓存")
+	}
+}
+
+// 测试延迟写入模式下删除与写入协程交错时不会把旧值写回Store
+func TestStoreDeleteDuringFlush(t *testing.T) {
+	t.Log("🔍 测试: 删除Store后、更新缓存前的写入不恢复已删除的键")
+	store := &hookStore{memStore: newMemStore[string, int]()}
+	cache := New[string, int](3).Bind(store).WriteBehind(0, 0)
+	defer cache.Close()
+
+	cache.Set("k", 1)
+	flushed := make(chan error)
+	store.onDelete = func() {
+		go func() { flushed <- cache.Flush() }()
+	}
+	if !cache.Delete("k") {
+		t.Fatal("❌ Delete应删除缓存项")
+	}
+	if err := <-flushed; err != nil {
+		t.Errorf("❌ Flush失败: %v", err)
+	}
+	if v, ok := store.get("k"); ok {
+		t.Errorf("❌ 已删除的键不应被写回Store, 实际 %v", v)
+	} else {
+		t.Log("✅ 删除期间的Flush没有写回旧值")
+	}
+}
+
+// 测试写入Store后缓存被置顶项占满时删除旧项
+func TestStoreWriteThenFull(t *testing.T) {
+	t.Log("🔍 测试: Store已更新但缓存拒绝写入时不保留旧值")
+	store := &hookStore{memStore: newMemStore[string, int]()}
+	cache := New[string, int](3).Bind(store).MaxPinned(1)
+
+	cache.Set("k", 1)
+	cache.Set("x", 0)
+	store.onStore = func() { cache.Pin("x") }
+	if err := cache.SetWithOptions("k", 2, EntryOptions{Pinned: true}); !errors.Is(err, ErrFull) {
+		t.Fatalf("❌ 置顶项达到上限时应返回ErrFull, 实际 %v", err)
+	}
+	if v, ok := store.get("k"); !ok || v != 2 {
+		t.Errorf("❌ Store应已写入新值: %v, %v", v, ok)
+	}
+	if v, ok := cache.Peek("k"); ok {
+		t.Errorf("❌ 缓存不应继续返回旧值, 实际 %v", v)
+	} else {
+		t.Log("✅ 删除了与Store不一致的旧值")
+	}
+}
@@ -0,0 +1,328 @@
+package lru
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Tier 是二级缓存需要实现的接口
+// 可以是另一个Cache（通过AsTier适配）、磁盘缓存或远程缓存客户端，实现必须是并发安全的
+type Tier[K comparable, V any] interface {
+	// Fetch 获取键对应的值及其过期时间点，零值表示永不过期
+	Fetch(key K) (V, time.Time, bool)
+	// Put 写入键值对及其过期时间点，零值表示永不过期
+	Put(key K, value V, expireAt time.Time) error
+	// Remove 删除键，返回键是否存在
+	Remove(key K) (bool, error)
+	// Clear 删除所有键
+	Clear() error
+}
+
+// TieredCache 是由进程内L1缓存和可插拔L2缓存组成的两级缓存
+// L2命中时会将该项提升到L1，开启降级后L1因容量淘汰的项会写入L2
+type TieredCache[K comparable, V any] struct {
+	locks   [keyLockStripes]sync.Mutex // 按键串行化L2访问与Set/Delete，避免提升旧值覆盖新值
+	l1      *Cache[K, V]               // 一级缓存
+	l2      Tier[K, V]                 // 二级缓存
+	queueMu sync.Mutex                 // 保护queue，可以在持有L1的锁时获取
+	queue   map[K]demoted[V]           // 已被L1淘汰、尚未写入L2的项
+	errMu   sync.Mutex                 // 保护l2Err
+	l2Err   error                      // 降级写入L2或使L2中的项失效时的错误，由Close报告
+}
+
+// demoted 是等待降级写入L2的项
+type demoted[V any] struct {
+	value    V         // 被淘汰的值
+	expireAt time.Time // 过期时间点，零值表示永不过期
+}
+
+// NewTiered 创建两级缓存
+// 参数 l1: 一级缓存，通常容量较小
+// 参数 l2: 二级缓存
+func NewTiered[K comparable, V any](l1 *Cache[K, V], l2 Tier[K, V]) *TieredCache[K, V] {
+	return &TieredCache[K, V]{l1: l1, l2: l2}
+}
+
+// Demote 设置是否将L1因容量淘汰的项降级写入L2
+// 参数 enable: 为true时L1淘汰的项会写入L2，不影响L1的OnEvict回调
+// 返回两级缓存实例本身，支持链式调用
+// 淘汰时只在持有L1的锁时记录被淘汰的项，由触发淘汰的TieredCache操作在释放L1的锁后写入L2，
+// 较慢的L2不会阻塞L1的其他操作；直接操作L1或释放句柄引起的降级在下一次TieredCache操作或Close时写入，
+// 写入前Get和Peek仍能读到该项
+// 被淘汰的项有未释放的句柄时，降级推迟到句柄释放，期间该键被重新写入、删除或清空时不再降级
+func (t *TieredCache[K, V]) Demote(enable bool) *TieredCache[K, V] {
+	t.l1.mu.Lock()
+	defer t.l1.mu.Unlock()
+
+	if !enable {
+		t.l1.onDemote = nil
+		return t
+	}
+	t.l1.onDemote = func(key K, value V, expireAt time.Time) {
+		t.queueMu.Lock()
+		if t.queue == nil {
+			t.queue = make(map[K]demoted[V])
+		}
+		t.queue[key] = demoted[V]{value, expireAt}
+		t.queueMu.Unlock()
+	}
+	return t
+}
+
+// demote 将等待降级的项写入L2
+// 调用前不能持有任何键锁
+func (t *TieredCache[K, V]) demote() {
+	for {
+		t.queueMu.Lock()
+		var (
+			key   K
+			found bool
+		)
+		for key = range t.queue {
+			found = true
+			break
+		}
+		t.queueMu.Unlock()
+		if !found {
+			return
+		}
+
+		// 持有键锁时取出并写入，Set和Delete不会在写入之后被旧值覆盖
+		l := t.lock(key)
+		if d, ok := t.take(key); ok {
+			if err := t.l2.Put(key, d.value, d.expireAt); err != nil {
+				t.record(err)
+			}
+		}
+		l.Unlock()
+	}
+}
+
+// take 取出key等待降级的项
+func (t *TieredCache[K, V]) take(key K) (demoted[V], bool) {
+	t.queueMu.Lock()
+	defer t.queueMu.Unlock()
+
+	d, ok := t.queue[key]
+	delete(t.queue, key)
+	return d, ok
+}
+
+// queued 返回key等待降级且未过期的项
+func (t *TieredCache[K, V]) queued(key K) (demoted[V], bool) {
+	t.queueMu.Lock()
+	defer t.queueMu.Unlock()
+
+	d, ok := t.queue[key]
+	if ok && !d.expireAt.IsZero() && !time.Now().Before(d.expireAt) {
+		return d, false
+	}
+	return d, ok
+}
+
+// record 记录L2操作的错误，由Close报告
+func (t *TieredCache[K, V]) record(err error) {
+	t.errMu.Lock()
+	t.l2Err = errors.Join(t.l2Err, err)
+	t.errMu.Unlock()
+}
+
+// lock 获取key对应的锁，不同的键可能共用同一个锁
+// 返回值: 已获取的锁，由调用者释放
+func (t *TieredCache[K, V]) lock(key K) *sync.Mutex {
+	l := &t.locks[stripe(key)]
+	l.Lock()
+	return l
+}
+
+// Get 获取缓存项，L1未命中时查找L2，L2命中会将该项提升到L1，L1被置顶项占满时不提升
+// 参数 key: 要获取的缓存项键
+// 返回值: 缓存项的值和是否存在的标志
+// 查找L2时只与同一个键（及共用锁的少数键）的操作串行，不同键的L1未命中可以并发访问L2
+func (t *TieredCache[K, V]) Get(key K) (V, bool) {
+	if value, ok := t.l1.Get(key); ok {
+		return value, true
+	}
+
+	defer t.demote()
+	l := t.lock(key)
+	defer l.Unlock()
+
+	// 等待降级的项比L2中的值更新，L1被置顶项占满无法提升时继续等待降级
+	if d, ok := t.queued(key); ok {
+		if t.l1.setAt(key, d.value, d.expireAt) == nil {
+			t.take(key)
+		}
+		return d.value, true
+	}
+	value, expireAt, ok := t.l2.Fetch(key)
+	if !ok {
+		return value, false
+	}
+	t.l1.setAt(key, value, expireAt)
+	return value, true
+}
+
+// Peek 获取缓存项但不更新L1的位置，也不会将L2中的项提升到L1
+func (t *TieredCache[K, V]) Peek(key K) (V, bool) {
+	if value, ok := t.l1.Peek(key); ok {
+		return value, true
+	}
+
+	// 与降级串行，避免在等待降级的项取出后、写入L2前未命中
+	l := t.lock(key)
+	defer l.Unlock()
+
+	if d, ok := t.queued(key); ok {
+		return d.value, true
+	}
+
+	value, _, ok := t.l2.Fetch(key)
+	return value, ok
+}
+
+// Set 将缓存项写入L1，并使L2中的旧值失效
+// 返回值: 指向L1中该缓存项的句柄
+// L2删除失败时旧值可能在L1淘汰该项后重新被返回，错误由Close报告
+func (t *TieredCache[K, V]) Set(key K, value V) EntryOption[K, V] {
+	defer t.demote()
+	l := t.lock(key)
+	defer l.Unlock()
+
+	t.take(key)
+	if _, err := t.l2.Remove(key); err != nil {
+		t.record(err)
+	}
+	return t.l1.Set(key, value)
+}
+
+// Delete 从两级缓存中删除缓存项
+// 返回值: 任意一级中是否找到并删除了该项
+// L2删除失败时旧值可能在之后被重新返回，错误由Close报告
+func (t *TieredCache[K, V]) Delete(key K) bool {
+	l := t.lock(key)
+	defer l.Unlock()
+
+	deleted := t.l1.Delete(key)
+	_, queued := t.take(key)
+	ok, err := t.l2.Remove(key)
+	if err != nil {
+		t.record(err)
+	}
+	return deleted || queued || ok
+}
+
+// Size 返回L1中的项数
+func (t *TieredCache[K, V]) Size() int {
+	return t.l1.Size()
+}
+
+// Capacity 返回L1的容量
+func (t *TieredCache[K, V]) Capacity() int {
+	return t.l1.Capacity()
+}
+
+// SetCapacity 调整L1的容量，开启降级时被淘汰的项会写入L2
+func (t *TieredCache[K, V]) SetCapacity(size int) {
+	t.l1.SetCapacity(size)
+	t.demote()
+}
+
+// Keys 返回L1中所有未过期的键，按照最近使用顺序排列
+func (t *TieredCache[K, V]) Keys() []K {
+	return t.l1.Keys()
+}
+
+// Range 遍历L1中所有未过期的缓存项
+func (t *TieredCache[K, V]) Range(fn func(K, V) bool) {
+	t.l1.Range(fn)
+}
+
+// Purge 清理L1中所有过期项，返回清理的项数
+func (t *TieredCache[K, V]) Purge() int {
+	return t.l1.Purge()
+}
+
+// Clear 清空两级缓存
+// 会等待所有进行中的L2查找、Set和Delete完成
+func (t *TieredCache[K, V]) Clear() {
+	for i := range t.locks {
+		t.locks[i].Lock()
+		defer t.locks[i].Unlock()
+	}
+
+	t.l1.Clear()
+	t.queueMu.Lock()
+	t.queue = nil
+	t.queueMu.Unlock()
+	t.l2.Clear()
+}
+
+// Close 关闭L1缓存，并将等待降级的项写入L2
+// 返回值: L1关闭时的错误，以及降级写入L2和Set、Delete使L2失效时累积的错误
+func (t *TieredCache[K, V]) Close() error {
+	err := t.l1.Close()
+	t.demote()
+
+	t.errMu.Lock()
+	defer t.errMu.Unlock()
+
+	err = errors.Join(err, t.l2Err)
+	t.l2Err = nil
+	return err
+}
+
+// AsTier 将Cache适配为Tier，用作两级缓存的L2
+func AsTier[K comparable, V any](c *Cache[K, V]) Tier[K, V] {
+	return cacheTier[K, V]{c}
+}
+
+// cacheTier 是Cache的Tier适配器
+type cacheTier[K comparable, V any] struct {
+	c *Cache[K, V]
+}
+
+func (t cacheTier[K, V]) Fetch(key K) (V, time.Time, bool) {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+
+	var zero V
+	value, result := t.c.lookup(key, true)
+	if result != LookupHit {
+		return zero, time.Time{}, false
+	}
+	return value, t.c.items[key].entry.expireAt, true
+}
+
+func (t cacheTier[K, V]) Put(key K, value V, expireAt time.Time) error {
+	return t.c.setAt(key, value, expireAt)
+}
+
+func (t cacheTier[K, V]) Remove(key K) (bool, error) {
+	return t.c.TryDelete(key)
+}
+
+func (t cacheTier[K, V]) Clear() error {
+	t.c.Clear()
+	return nil
+}
+
+// setAt 以指定的过期时间点写入缓存项，不写入绑定的Store
+// 参数 expireAt: 过期时间点，零值表示永不过期
+// 返回值: 缓存已关闭时返回ErrClosed，缓存被置顶项占满时返回ErrFull
+func (c *Cache[K, V]) setAt(key K, value V, expireAt time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	if err := c.admit(key, false); err != nil {
+		return err
+	}
+	item := entry[K, V]{key: key, value: value, expireAt: expireAt}
+	c.put(item)
+	c.logOp(item.record(walSet))
+	return nil
+}
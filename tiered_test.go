@@ -0,0 +1,282 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// 测试两级缓存的提升和降级
+func TestTieredPromoteDemote(t *testing.T) {
+	t.Log("🔍 测试: 两级缓存的提升和降级")
+	l1 := New[string, int](2)
+	l2 := New[string, int](10)
+	cache := NewTiered(l1, AsTier(l2)).Demote(true)
+
+	cache.Set("a", 1).Expire(time.Minute)
+	cache.Set("b", 2)
+	cache.Set("c", 3) // "a"被L1淘汰并降级到L2
+
+	if _, ok := l1.Peek("a"); ok {
+		t.Fatal("❌ 'a'应已被L1淘汰")
+	}
+	if v, ok := l2.Peek("a"); !ok || v != 1 {
+		t.Fatalf("❌ 'a'应降级到L2: %v, %v", v, ok)
+	}
+	t.Log("✅ L1淘汰的项降级到L2")
+
+	if _, expireAt, _ := AsTier(l2).Fetch("a"); expireAt.IsZero() {
+		t.Error("❌ 降级时应保留过期时间")
+	}
+
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Fatalf("❌ 应从L2获取到'a': %v, %v", v, ok)
+	}
+	if v, ok := l1.Peek("a"); !ok || v != 1 {
+		t.Errorf("❌ L2命中后应提升到L1: %v, %v", v, ok)
+	} else {
+		t.Log("✅ L2命中后提升到L1")
+	}
+}
+
+// 测试两级缓存的一致性删除和失效
+func TestTieredInvalidation(t *testing.T) {
+	t.Log("🔍 测试: 两级缓存的删除和失效")
+	l1 := New[string, int](2)
+	l2 := New[string, int](10)
+	cache := NewTiered(l1, AsTier(l2))
+
+	l2.Set("a", 1)
+	cache.Set("a", 10)
+	if _, ok := l2.Peek("a"); ok {
+		t.Error("❌ Set应使L2中的旧值失效")
+	}
+
+	l2.Set("b", 2)
+	if v, ok := cache.Peek("b"); !ok || v != 2 {
+		t.Errorf("❌ Peek应能读取L2: %v, %v", v, ok)
+	}
+	if _, ok := l1.Peek("b"); ok {
+		t.Error("❌ Peek不应提升到L1")
+	}
+
+	cache.Get("b")
+	if !cache.Delete("b") {
+		t.Error("❌ Delete应返回true")
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Error("❌ 'b'应已从两级缓存中删除")
+	} else {
+		t.Log("✅ Delete同时删除两级缓存")
+	}
+
+	cache.Clear()
+	if l1.Size() != 0 || l2.Size() != 0 {
+		t.Errorf("❌ Clear应清空两级缓存: L1=%d, L2=%d", l1.Size(), l2.Size())
+	} else {
+		t.Log("✅ Clear清空两级缓存")
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Errorf("❌ Close返回错误: %v", err)
+	}
+}
+
+// 测试句柄释放时不会将旧值降级到L2
+func TestTieredDeferredDemote(t *testing.T) {
+	t.Log("🔍 测试: 推迟的降级回调不覆盖新值")
+	l1 := New[string, int](1)
+	l2 := New[string, int](10)
+	cache := NewTiered(l1, AsTier(l2)).Demote(true)
+
+	cache.Set("k", 1)
+	h, ok := l1.Acquire("k")
+	if !ok {
+		t.Fatal("❌ Acquire失败")
+	}
+	cache.Set("x", 0) // "k"被淘汰，降级推迟到句柄释放
+	cache.Set("k", 2)
+	cache.Set("y", 0) // 新的"k"被淘汰并降级到L2
+	h.Release()
+
+	if v, ok := l2.Peek("k"); !ok || v != 2 {
+		t.Errorf("❌ L2中的'k'应为新值2, 实际%v, %v", v, ok)
+	} else {
+		t.Log("✅ 释放句柄没有用旧值覆盖L2")
+	}
+	if v, _ := cache.Get("k"); v != 2 {
+		t.Errorf("❌ 两级缓存应返回新值2, 实际%v", v)
+	}
+}
+
+// 测试推迟降级期间删除该键后不会降级到L2
+func TestTieredDeferredDemoteDeleted(t *testing.T) {
+	t.Log("🔍 测试: 已删除的键在句柄释放时不降级")
+	var finalized []int
+	l1 := New[string, int](1).OnEvict(func(_ string, value int, _ time.Time) {
+		finalized = append(finalized, value)
+	})
+	l2 := New[string, int](10)
+	cache := NewTiered(l1, AsTier(l2)).Demote(true)
+
+	cache.Set("k", 1)
+	h, _ := l1.Acquire("k")
+	cache.Set("x", 0) // "k"被淘汰，降级推迟到句柄释放
+	cache.Delete("k")
+	h.Release()
+
+	if v, ok := cache.Get("k"); ok {
+		t.Errorf("❌ 已删除的'k'不应降级到L2, 实际%v", v)
+	} else {
+		t.Log("✅ 释放句柄没有恢复已删除的键")
+	}
+	if !slices.Contains(finalized, 1) {
+		t.Errorf("❌ 降级不应替换L1的OnEvict回调, 实际%v", finalized)
+	}
+}
+
+// failingTier 是Remove总是返回错误的测试Tier
+type failingTier struct {
+	Tier[string, int]
+	err error
+}
+
+func (f failingTier) Remove(key string) (bool, error) {
+	return false, f.err
+}
+
+// 测试L2失效失败时的错误报告
+func TestTieredRemoveError(t *testing.T) {
+	t.Log("🔍 测试: Set和Delete使L2失效失败时由Close报告错误")
+	errRemove := errors.New("remove failed")
+	cache := NewTiered(New[string, int](2), failingTier{AsTier(New[string, int](10)), errRemove})
+
+	cache.Set("a", 1)
+	cache.Delete("a")
+	if err := cache.Close(); !errors.Is(err, errRemove) {
+		t.Errorf("❌ Close应报告L2删除错误, 实际 %v", err)
+	} else {
+		t.Log("✅ L2删除错误没有被丢弃")
+	}
+}
+
+// slowTier 是获取或写入"a"时阻塞直到release被关闭的测试Tier
+type slowTier struct {
+	Tier[string, int]
+	started chan struct{}
+	release chan struct{}
+}
+
+// wait 通知开始访问"a"并等待release
+func (s slowTier) wait(key string) {
+	if key == "a" {
+		close(s.started)
+		<-s.release
+	}
+}
+
+func (s slowTier) Fetch(key string) (int, time.Time, bool) {
+	s.wait(key)
+	return s.Tier.Fetch(key)
+}
+
+func (s slowTier) Put(key string, value int, expireAt time.Time) error {
+	s.wait(key)
+	return s.Tier.Put(key, value, expireAt)
+}
+
+// 测试L2查找只阻塞同一个键
+func TestTieredFetchDoesNotBlockOtherKeys(t *testing.T) {
+	t.Log("🔍 测试: 较慢的L2查找期间其他键的L1未命中不被阻塞")
+	l2 := New[string, int](10)
+	l2.Set("a", 1)
+	l2.Set("b", 2)
+	slow := slowTier{AsTier(l2), make(chan struct{}), make(chan struct{})}
+	cache := NewTiered(New[string, int](2), slow)
+
+	// 选择与"a"不共用锁的键
+	other := "b"
+	for i := 0; stripe(other) == stripe("a"); i++ {
+		other = fmt.Sprintf("b%d", i)
+		l2.Set(other, 2)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cache.Get("a")
+		close(done)
+	}()
+	<-slow.started
+
+	if v, ok := cache.Get(other); !ok || v != 2 {
+		t.Errorf("❌ 应能在查找'a'期间从L2获取其他键: %v, %v", v, ok)
+	} else {
+		t.Log("✅ 其他键的L2查找没有被阻塞")
+	}
+	close(slow.release)
+	<-done
+}
+
+// 测试降级写入L2时不持有L1的锁
+func TestTieredDemoteDoesNotBlockL1(t *testing.T) {
+	t.Log("🔍 测试: 较慢的降级写入期间L1可以正常读写")
+	l1 := New[string, int](2)
+	l2 := New[string, int](10)
+	slow := slowTier{AsTier(l2), make(chan struct{}), make(chan struct{})}
+	cache := NewTiered(l1, slow).Demote(true)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	done := make(chan struct{})
+	go func() {
+		cache.Set("c", 3) // "a"被淘汰，降级写入L2时阻塞
+		close(done)
+	}()
+	<-slow.started
+
+	if v, ok := cache.Get("b"); !ok || v != 2 {
+		t.Errorf("❌ 降级期间应能读取L1: %v, %v", v, ok)
+	}
+	l1.Set("d", 4)
+	if v, ok := l1.Peek("d"); !ok || v != 4 {
+		t.Errorf("❌ 降级期间应能写入L1: %v, %v", v, ok)
+	} else {
+		t.Log("✅ 降级写入没有阻塞L1")
+	}
+	close(slow.release)
+	<-done
+
+	if v, ok := l2.Peek("a"); !ok || v != 1 {
+		t.Errorf("❌ 'a'应降级到L2: %v, %v", v, ok)
+	}
+	if v, ok := cache.Get("c"); !ok || v != 3 {
+		t.Errorf("❌ 直接写入L1淘汰的'c'应能读取: %v, %v", v, ok)
+	}
+}
+
+// 测试L1被置顶项占满时不提升L2中的项
+func TestTieredPromoteFull(t *testing.T) {
+	t.Log("🔍 测试: L1被置顶项占满时L2命中不提升")
+	var evicted []string
+	l1 := New[string, int](1).OnEvict(func(key string, _ int, _ time.Time) {
+		evicted = append(evicted, key)
+	})
+	l2 := New[string, int](10)
+	cache := NewTiered(l1, AsTier(l2)).Demote(true)
+
+	l1.SetWithOptions("p", 0, EntryOptions{Pinned: true})
+	l2.Set("a", 1)
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Fatalf("❌ 应从L2获取到'a': %v, %v", v, ok)
+	}
+	if _, ok := l1.Peek("a"); ok || len(evicted) != 0 {
+		t.Errorf("❌ 不应提升到L1或触发淘汰回调, 淘汰%v", evicted)
+	} else {
+		t.Log("✅ 没有提升后立即淘汰")
+	}
+	if v, ok := l2.Peek("a"); !ok || v != 1 {
+		t.Errorf("❌ L2中的'a'应保留: %v, %v", v, ok)
+	}
+}
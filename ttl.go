@@ -0,0 +1,77 @@
+package lru
+
+import "time"
+
+// TTLOf 返回缓存项的剩余生存时间
+// 参数 key: 缓存项的键
+// 返回值: 剩余生存时间和键是否存在，永不过期时剩余时间为0；
+// 已过期的项、负缓存项和错误项视为不存在
+func (c *Cache[K, V]) TTLOf(key K) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := c.now()
+	e, ok := c.live(key, now)
+	if !ok || e.entry.expireAt.IsZero() {
+		return 0, ok
+	}
+	return e.entry.expireAt.Sub(now), true
+}
+
+// Touch 使用默认过期时间重新计算缓存项的过期时间，不改变值和位置
+// 参数 key: 缓存项的键
+// 返回值: 键是否存在，未设置默认过期时间时该项变为永不过期
+func (c *Cache[K, V]) Touch(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	e, ok := c.live(key, now)
+	if ok {
+		expireAt := time.Time{}
+		if c.ttl > 0 {
+			expireAt = now.Add(c.ttl)
+		}
+		c.setExpireAt(e, expireAt)
+	}
+	return ok
+}
+
+// Persist 清除缓存项的过期时间，使其永不过期
+// 参数 key: 缓存项的键
+// 返回值: 键是否存在
+func (c *Cache[K, V]) Persist(key K) bool {
+	return c.ExpireAt(key, time.Time{})
+}
+
+// ExpireAt 将缓存项的过期时间设为指定的时间点
+// 参数 key: 缓存项的键
+// 参数 deadline: 过期时间点，零值表示永不过期，早于当前时间时该项立即过期
+// 返回值: 键是否存在
+func (c *Cache[K, V]) ExpireAt(key K, deadline time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.live(key, c.now())
+	if ok {
+		c.setExpireAt(e, deadline)
+	}
+	return ok
+}
+
+// live 查找未过期的有效值，不更新位置也不删除过期项
+// 调用前必须持有读锁或写锁
+func (c *Cache[K, V]) live(key K, now time.Time) (*node[K, V], bool) {
+	e, ok := c.items[key]
+	if !ok || !e.entry.hasValue() || e.entry.expired(now) {
+		return nil, false
+	}
+	return e, true
+}
+
+// setExpireAt 修改缓存项的过期时间点并记录到预写日志
+// 调用前必须持有锁
+func (c *Cache[K, V]) setExpireAt(e *node[K, V], expireAt time.Time) {
+	e.entry.expireAt = expireAt
+	c.logOp(walRecord[K, V]{Op: walExpire, Key: e.entry.key, ExpireAt: expireAt})
+}
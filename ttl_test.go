@@ -0,0 +1,107 @@
+package lru
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 测试查询和修改单个缓存项的过期时间
+func TestTTLOperations(t *testing.T) {
+	t.Log("🔍 测试: TTLOf、Touch、Persist和ExpireAt")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cache := New[string, int](5).TTL(time.Minute)
+	cache.now = clock.Now
+
+	cache.Set("a", 1)
+	clock.Advance(20 * time.Second)
+	if ttl, ok := cache.TTLOf("a"); !ok || ttl != 40*time.Second {
+		t.Errorf("❌ 剩余TTL应为40s, 实际%v, %v", ttl, ok)
+	} else {
+		t.Log("✅ TTLOf返回剩余生存时间")
+	}
+
+	if !cache.Touch("a") {
+		t.Error("❌ Touch应报告键存在")
+	}
+	if ttl, _ := cache.TTLOf("a"); ttl != time.Minute {
+		t.Errorf("❌ Touch后剩余TTL应为1m, 实际%v", ttl)
+	} else {
+		t.Log("✅ Touch使用默认TTL刷新过期时间")
+	}
+
+	if !cache.Persist("a") {
+		t.Error("❌ Persist应报告键存在")
+	}
+	clock.Advance(time.Hour)
+	if ttl, ok := cache.TTLOf("a"); !ok || ttl != 0 {
+		t.Errorf("❌ Persist后应永不过期, 实际%v, %v", ttl, ok)
+	} else {
+		t.Log("✅ Persist清除过期时间")
+	}
+
+	deadline := clock.Now().Add(10 * time.Second)
+	if !cache.ExpireAt("a", deadline) {
+		t.Error("❌ ExpireAt应报告键存在")
+	}
+	clock.Advance(10 * time.Second)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("❌ 到达ExpireAt设置的时间点后应过期")
+	} else {
+		t.Log("✅ ExpireAt设置绝对过期时间点")
+	}
+
+	cache.SetMissing("m", 0)
+	for _, key := range []string{"a", "m", "none"} {
+		if _, ok := cache.TTLOf(key); ok || cache.Touch(key) || cache.Persist(key) || cache.ExpireAt(key, deadline) {
+			t.Errorf("❌ %s: 不存在、已过期或负缓存的项应报告不存在", key)
+		}
+	}
+	t.Log("✅ 不存在的键全部报告false")
+
+	cache.TTL(0).Set("c", 1).Expire(time.Second)
+	if !cache.Touch("c") {
+		t.Error("❌ Touch应报告键存在")
+	}
+	if ttl, ok := cache.TTLOf("c"); !ok || ttl != 0 {
+		t.Errorf("❌ 没有默认TTL时Touch后应永不过期, 实际%v", ttl)
+	}
+}
+
+// 测试过期时间修改写入预写日志
+func TestTTLOperationsWAL(t *testing.T) {
+	t.Log("🔍 测试: Touch、Persist和ExpireAt在恢复后仍然生效")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := New[string, int](5).TTL(time.Minute)
+	cache.now = clock.Now
+	if err := cache.OpenWAL(path, SyncNever); err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("touch", 1)
+	cache.Set("persist", 2)
+	cache.Set("deadline", 3)
+	clock.Advance(30 * time.Second)
+	cache.Touch("touch")
+	cache.Persist("persist")
+	cache.ExpireAt("deadline", clock.Now().Add(time.Hour))
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered := New[string, int](5)
+	recovered.now = clock.Now
+	if err := recovered.OpenWAL(path, SyncNever); err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.Close()
+
+	want := map[string]time.Duration{"touch": time.Minute, "persist": 0, "deadline": time.Hour}
+	for key, ttl := range want {
+		if got, ok := recovered.TTLOf(key); !ok || got != ttl {
+			t.Errorf("❌ %s: 恢复后剩余TTL应为%v, 实际%v, %v", key, ttl, got, ok)
+		}
+	}
+	t.Log("✅ 恢复后过期时间与关闭前一致")
+}
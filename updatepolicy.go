@@ -0,0 +1,70 @@
+package lru
+
+import "time"
+
+// UpdatePolicy 决定Set等使用默认过期时间的写入在更新已有项时如何计算过期时间
+// 只影响更新未过期的有效项，新增项、替换负缓存项或已过期项时总是使用默认过期时间；
+// SetWithTTL和指定了过期时间的SetWithOptions不受影响
+type UpdatePolicy int
+
+const (
+	// UpdateRefreshExpiring 永不过期的项保持永不过期，其他项按默认过期时间重新计算，为默认策略
+	UpdateRefreshExpiring UpdatePolicy = iota
+	// UpdateRefresh 总是按默认过期时间重新计算，永不过期的项也会变为会过期
+	UpdateRefresh
+	// UpdateKeep 保留原有的过期时间
+	UpdateKeep
+	// UpdateKeepLonger 在原有过期时间和按默认过期时间重新计算的结果中取较晚的一个，
+	// 永不过期视为最晚
+	UpdateKeepLonger
+)
+
+// valid 检查策略是否为已定义的值
+func (p UpdatePolicy) valid() bool {
+	return p >= UpdateRefreshExpiring && p <= UpdateKeepLonger
+}
+
+// UpdatePolicy 设置更新已有项时过期时间的计算方式
+// 参数 p: 更新策略，未定义的值按UpdateRefreshExpiring处理
+// 返回缓存实例本身，支持链式调用
+func (c *Cache[K, V]) UpdatePolicy(p UpdatePolicy) *Cache[K, V] {
+	if !p.valid() {
+		p = UpdateRefreshExpiring
+	}
+	c.mu.Lock()
+	c.updatePolicy = p
+	c.mu.Unlock()
+	return c
+}
+
+// defaultExpireAt 返回使用默认过期时间写入key时的过期时间点
+// 更新已有项时按UpdatePolicy设置的策略计算
+// 调用前必须持有锁
+func (c *Cache[K, V]) defaultExpireAt(key K) time.Time {
+	now := c.now()
+	var expireAt time.Time
+	if c.ttl > 0 {
+		expireAt = now.Add(c.ttl)
+	}
+
+	e, ok := c.live(key, now)
+	if !ok {
+		return expireAt
+	}
+	prev := e.entry.expireAt
+	switch c.updatePolicy {
+	case UpdateRefresh:
+		return expireAt
+	case UpdateKeep:
+		return prev
+	case UpdateKeepLonger:
+		if prev.IsZero() || (!expireAt.IsZero() && prev.After(expireAt)) {
+			return prev
+		}
+		return expireAt
+	}
+	if prev.IsZero() {
+		return prev
+	}
+	return expireAt
+}
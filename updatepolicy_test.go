@@ -0,0 +1,72 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+// 测试更新已有项时的过期时间策略
+func TestUpdatePolicy(t *testing.T) {
+	t.Log("🔍 测试: 不同更新策略下Set对过期时间的影响")
+	tests := []struct {
+		name       string
+		policy     UpdatePolicy
+		persistent time.Duration // 原本永不过期的项更新后的剩余TTL
+		short      time.Duration // 原本剩余10s的项更新后的剩余TTL
+		long       time.Duration // 原本剩余1h的项更新后的剩余TTL
+	}{
+		{"UpdateRefreshExpiring", UpdateRefreshExpiring, 0, time.Minute, time.Minute},
+		{"UpdateRefresh", UpdateRefresh, time.Minute, time.Minute, time.Minute},
+		{"UpdateKeep", UpdateKeep, 0, 10 * time.Second, time.Hour},
+		{"UpdateKeepLonger", UpdateKeepLonger, 0, time.Minute, time.Hour},
+	}
+
+	for _, tt := range tests {
+		clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+		cache := New[string, int](5).TTL(time.Minute).UpdatePolicy(tt.policy)
+		cache.now = clock.Now
+
+		cache.Set("persistent", 1).Expire(0)
+		cache.Set("short", 1).Expire(10 * time.Second)
+		cache.Set("long", 1).Expire(time.Hour)
+		for _, key := range []string{"persistent", "short", "long"} {
+			cache.Set(key, 2)
+		}
+
+		got := [3]time.Duration{}
+		for i, key := range []string{"persistent", "short", "long"} {
+			got[i], _ = cache.TTLOf(key)
+		}
+		if want := [3]time.Duration{tt.persistent, tt.short, tt.long}; got != want {
+			t.Errorf("❌ %s: 剩余TTL应为%v, 实际%v", tt.name, want, got)
+		} else {
+			t.Logf("✅ %s: %v", tt.name, got)
+		}
+
+		// 新增项和替换已过期项总是使用默认过期时间
+		cache.Set("new", 1)
+		clock.Advance(20 * time.Second)
+		cache.Set("short", 3)
+		if ttl, _ := cache.TTLOf("new"); ttl != 40*time.Second {
+			t.Errorf("❌ %s: 新增项应使用默认TTL, 剩余%v", tt.name, ttl)
+		}
+		if tt.policy == UpdateKeep {
+			if ttl, ok := cache.TTLOf("short"); !ok || ttl != time.Minute {
+				t.Errorf("❌ %s: 替换已过期项应使用默认TTL, 实际%v, %v", tt.name, ttl, ok)
+			}
+		}
+	}
+
+	if _, err := NewWithOptions[string, int](WithUpdatePolicy(UpdatePolicy(99))); err == nil {
+		t.Error("❌ 未定义的更新策略应返回错误")
+	}
+	cache, err := NewWithOptions[string, int](WithTTL(time.Minute), WithUpdatePolicy(UpdateKeep))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.Set("a", 1).Expire(time.Hour)
+	cache.Set("a", 2)
+	if ttl, _ := cache.TTLOf("a"); ttl <= time.Minute {
+		t.Errorf("❌ WithUpdatePolicy(UpdateKeep)应保留原有过期时间, 剩余%v", ttl)
+	}
+}
@@ -0,0 +1,81 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate 检查缓存内部数据结构的一致性
+// 返回值: 发现的所有不一致问题，nil表示一致
+// 检查映射和链表的大小是否一致、链表节点的链接是否正确、每个链表节点是否被其键正确映射、
+// 项数是否超出容量、脏项计数、总成本、优先级计数和置顶项计数是否正确以及标签索引是否与缓存项一致；
+// 会持有读锁遍历全部项，
+// 适用于测试和管理接口，不建议在热路径上调用
+func (c *Cache[K, V]) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var errs []error
+	if len(c.items) != c.list.Len() {
+		errs = append(errs, fmt.Errorf("lru: map has %d items but list has %d", len(c.items), c.list.Len()))
+	}
+	// 容量被调低时置顶项可以超出容量
+	if c.list.Len() > max(c.size, c.pinned) {
+		errs = append(errs, fmt.Errorf("lru: size %d exceeds capacity %d", c.list.Len(), c.size))
+	}
+
+	dirty, prioritized, pinned, tagged := 0, 0, 0, 0
+	var cost int64
+	for e := c.list.Front(); e != nil; e = e.Next() {
+		if e.list != c.list || e.next.prev != e || e.prev.next != e {
+			errs = append(errs, fmt.Errorf("lru: list links broken at key %v", e.entry.key))
+			break
+		}
+		item := &e.entry
+		if mapped, ok := c.items[item.key]; !ok {
+			errs = append(errs, fmt.Errorf("lru: key %v in list but not in map", item.key))
+		} else if mapped != e {
+			errs = append(errs, fmt.Errorf("lru: key %v maps to a different list node", item.key))
+		}
+		if item.missing && (item.dirty || item.err != nil) {
+			errs = append(errs, fmt.Errorf("lru: negative entry %v is dirty or holds an error", item.key))
+		}
+		if item.dirty {
+			dirty++
+		}
+		if item.priority != 0 {
+			prioritized++
+		}
+		if item.pinned {
+			pinned++
+		}
+		cost += item.weight()
+		for _, tag := range item.tags {
+			if _, ok := c.tags[tag][item.key]; !ok {
+				errs = append(errs, fmt.Errorf("lru: key %v missing from index of tag %q", item.key, tag))
+			}
+			tagged++
+		}
+	}
+	if dirty != c.dirty {
+		errs = append(errs, fmt.Errorf("lru: dirty count is %d but %d entries are dirty", c.dirty, dirty))
+	}
+	if prioritized != c.prioritized {
+		errs = append(errs, fmt.Errorf("lru: prioritized count is %d but %d entries have a priority", c.prioritized, prioritized))
+	}
+	if pinned != c.pinned {
+		errs = append(errs, fmt.Errorf("lru: pinned count is %d but %d entries are pinned", c.pinned, pinned))
+	}
+	if cost != c.cost {
+		errs = append(errs, fmt.Errorf("lru: total cost is %d but entries sum to %d", c.cost, cost))
+	}
+	indexed := 0
+	for _, keys := range c.tags {
+		indexed += len(keys)
+	}
+	if indexed != tagged {
+		errs = append(errs, fmt.Errorf("lru: tag index has %d keys but entries carry %d tags", indexed, tagged))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,28 @@
+package lru
+
+import "testing"
+
+// 测试内部一致性检查
+func TestValidate(t *testing.T) {
+	t.Log("🔍 测试: Validate内部一致性检查")
+	cache := New[string, int](3)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.SetMissing("c", 0)
+	cache.Set("d", 4)
+	cache.Delete("b")
+
+	if err := cache.Validate(); err != nil {
+		t.Fatalf("❌ 正常缓存不应报告错误: %v", err)
+	}
+	t.Log("✅ 正常缓存通过检查")
+
+	// 人为破坏映射和链表的一致性
+	delete(cache.items, "a")
+	cache.dirty = 1
+	err := cache.Validate()
+	if err == nil {
+		t.Fatal("❌ 应检测到映射和链表不一致")
+	}
+	t.Logf("✅ 检测到不一致: %v", err)
+}
@@ -0,0 +1,281 @@
+package lru
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncPolicy 决定预写日志何时调用fsync
+// 正数n表示每写入n条记录同步一次，SyncNever表示只在Checkpoint和Close时同步
+type SyncPolicy int
+
+const (
+	// SyncNever 只在Checkpoint和Close时同步，崩溃时可能丢失操作系统尚未写盘的记录
+	SyncNever SyncPolicy = 0
+	// SyncAlways 每写入一条记录同步一次
+	SyncAlways SyncPolicy = 1
+)
+
+// SyncEvery 返回每写入n条记录同步一次的策略
+func SyncEvery(n int) SyncPolicy {
+	if n <= 0 {
+		return SyncNever
+	}
+	return SyncPolicy(n)
+}
+
+// walOp 是预写日志记录的操作类型
+type walOp uint8
+
+const (
+	walSet    walOp = iota + 1 // 添加或更新缓存项
+	walExpire                  // 修改过期时间
+	walDelete                  // 删除缓存项
+	walClear                   // 清空缓存
+	walPin                     // 修改置顶状态
+)
+
+// walRecord 是写入预写日志和快照的记录
+type walRecord[K comparable, V any] struct {
+	Op       walOp
+	Key      K
+	Value    V
+	ExpireAt time.Time
+	Cost     int64
+	Priority int
+	Tags     []string
+	Pinned   bool
+}
+
+// record 返回记录该缓存项的日志记录
+func (e *entry[K, V]) record(op walOp) walRecord[K, V] {
+	return walRecord[K, V]{
+		Op:       op,
+		Key:      e.key,
+		Value:    e.value,
+		ExpireAt: e.expireAt,
+		Cost:     e.cost,
+		Priority: e.priority,
+		Tags:     e.tags,
+		Pinned:   e.pinned,
+	}
+}
+
+// wal 是已打开的预写日志
+type wal struct {
+	file    *os.File   // 日志文件
+	path    string     // 日志文件路径，快照文件为path加上".snap"后缀
+	sync    SyncPolicy // fsync策略
+	pending int        // 上次同步后写入的记录数
+}
+
+// OpenWAL 打开预写日志，恢复数据后开始记录Set、Expire、Delete、Clear和置顶操作
+// 参数 path: 日志文件路径，快照保存在path加上".snap"后缀的文件中
+// 参数 sync: fsync策略
+// 返回值: 打开或恢复时的错误
+// 恢复时先加载最近一次Checkpoint写入的快照，再按顺序重放日志，
+// 日志末尾不完整或校验失败的记录会被截断；键和值使用encoding/gob编码
+// Get等读取不写入日志，恢复后的LRU顺序可能与关闭前不同，但容量淘汰和删除都会记录，恢复出的项相同；
+// 重放时因容量淘汰的历史值不会触发淘汰回调
+// 注意: 应在创建缓存后、开始使用前调用，重放会覆盖缓存中已有的同名项；
+// 使用NewWithOptions时可以通过WithWAL在创建缓存时恢复
+func (c *Cache[K, V]) OpenWAL(path string, sync SyncPolicy) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	if c.wal != nil {
+		return errors.New("lru: wal already open")
+	}
+
+	// 重放的是已经发生过的操作，不再为其中的淘汰调用回调或降级
+	onEvict, onDemote := c.onEvict, c.onDemote
+	c.onEvict, c.onDemote = nil, nil
+	defer func() {
+		c.onEvict, c.onDemote = onEvict, onDemote
+	}()
+
+	if snap, err := os.Open(path + ".snap"); err == nil {
+		_, err = scanRecords(snap, c.replay)
+		snap.Close()
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	size, err := scanRecords(file, c.replay)
+	if err == nil {
+		_, err = file.Seek(size, 0)
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	c.wal = &wal{file: file, path: path, sync: sync}
+	return nil
+}
+
+// Checkpoint 将当前缓存内容写入快照并清空预写日志
+// 返回值: 写入快照或截断日志时的错误
+// 快照先写入临时文件再原子替换，崩溃时最多丢失本次Checkpoint
+func (c *Cache[K, V]) Checkpoint() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	if c.wal == nil {
+		return errors.New("lru: wal not open")
+	}
+	c.drainReads()
+
+	tmp, err := os.OpenFile(c.wal.path+".snap.tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// 从最久未使用的项开始写入，重放时可以恢复LRU顺序
+	now := c.now()
+	for e := c.list.Back(); e != nil; e = e.Prev() {
+		item := &e.entry
+		if !item.hasValue() || item.expired(now) {
+			continue
+		}
+		buf, err := encodeRecord(item.record(walSet))
+		if err == nil {
+			_, err = tmp.Write(buf)
+		}
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.wal.path+".snap"); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	// 替换快照的目录项落盘之前不能截断日志，否则崩溃后可能只剩旧快照和空日志
+	if err := syncDir(filepath.Dir(c.wal.path)); err != nil {
+		return err
+	}
+
+	if err := c.wal.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := c.wal.file.Seek(0, 0); err != nil {
+		return err
+	}
+	c.wal.pending = 0
+	return c.wal.file.Sync()
+}
+
+// syncDir 同步目录，使目录中文件的创建和重命名落盘
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
+
+// replay 将一条快照或日志记录应用到缓存，不会写入日志
+// 调用前必须持有锁
+func (c *Cache[K, V]) replay(payload []byte, _, _ int64) error {
+	var rec walRecord[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec); err != nil {
+		return err
+	}
+
+	switch rec.Op {
+	case walSet:
+		c.put(entry[K, V]{
+			key:      rec.Key,
+			value:    rec.Value,
+			expireAt: rec.ExpireAt,
+			cost:     rec.Cost,
+			priority: rec.Priority,
+			tags:     rec.Tags,
+			pinned:   rec.Pinned,
+		})
+	case walExpire:
+		if e, ok := c.items[rec.Key]; ok {
+			item := &e.entry
+			item.expireAt = rec.ExpireAt
+		}
+	case walDelete:
+		if e, ok := c.items[rec.Key]; ok {
+			c.removeElement(e)
+		}
+	case walClear:
+		c.reset()
+	case walPin:
+		if e, ok := c.items[rec.Key]; ok {
+			c.untrack(&e.entry)
+			e.entry.pinned = rec.Pinned
+			c.track(&e.entry)
+		}
+	default:
+		return errors.New("lru: unknown wal operation")
+	}
+	return nil
+}
+
+// logOp 将一次操作追加到预写日志
+// 写入错误会保留到下一次Flush或Close时报告
+// 调用前必须持有锁
+func (c *Cache[K, V]) logOp(rec walRecord[K, V]) {
+	if c.wal == nil {
+		return
+	}
+
+	buf, err := encodeRecord(rec)
+	if err == nil {
+		_, err = c.wal.file.Write(buf)
+	}
+	if err == nil && c.wal.sync > SyncNever {
+		c.wal.pending++
+		if c.wal.pending >= int(c.wal.sync) {
+			c.wal.pending = 0
+			err = c.wal.file.Sync()
+		}
+	}
+	if err != nil {
+		c.flushErr = errors.Join(c.flushErr, err)
+	}
+}
+
+// closeWAL 同步并关闭预写日志
+// 调用前必须持有锁
+func (c *Cache[K, V]) closeWAL() error {
+	if c.wal == nil {
+		return nil
+	}
+	err := errors.Join(c.wal.file.Sync(), c.wal.file.Close())
+	c.wal = nil
+	return err
+}
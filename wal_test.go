@@ -0,0 +1,200 @@
+package lru
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 测试预写日志的记录和恢复
+func TestWALRecover(t *testing.T) {
+	t.Log("🔍 测试: 预写日志记录和恢复")
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := New[string, int](3)
+	if err := cache.OpenWAL(path, SyncAlways); err != nil {
+		t.Fatalf("❌ 打开预写日志失败: %v", err)
+	}
+	cache.Set("x", 0)
+	cache.Clear()
+	cache.Set("a", 1)
+	cache.Set("b", 2).Expire(time.Hour)
+	cache.Set("c", 3).Expire(10 * time.Millisecond)
+	cache.Delete("a")
+	cache.Set("d", 4)
+	if err := cache.Close(); err != nil {
+		t.Fatalf("❌ 关闭缓存失败: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	recovered := New[string, int](3)
+	if err := recovered.OpenWAL(path, SyncAlways); err != nil {
+		t.Fatalf("❌ 恢复失败: %v", err)
+	}
+	defer recovered.Close()
+
+	printCacheStatus(t, recovered)
+	if keys := recovered.Keys(); len(keys) != 2 || keys[0] != "d" || keys[1] != "b" {
+		t.Errorf("❌ 恢复后的键应为[d b], 实际 %v", keys)
+	} else {
+		t.Log("✅ 重放Set、Expire、Delete和Clear后状态正确")
+	}
+	if _, ok := recovered.Get("c"); ok {
+		t.Error("❌ 已过期的'c'不应恢复")
+	}
+}
+
+// 测试快照和日志截断
+func TestWALCheckpoint(t *testing.T) {
+	t.Log("🔍 测试: Checkpoint写入快照并清空日志")
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := New[string, int](5)
+	if err := cache.OpenWAL(path, SyncNever); err != nil {
+		t.Fatalf("❌ 打开预写日志失败: %v", err)
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	if err := cache.Checkpoint(); err != nil {
+		t.Fatalf("❌ Checkpoint失败: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Errorf("❌ Checkpoint后日志应为空, 实际%d字节", info.Size())
+	}
+	cache.Set("c", 3)
+	cache.Close()
+
+	// 模拟崩溃：在日志末尾写入不完整的记录
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	f.Write([]byte{0xff, 0x00, 0x00})
+	f.Close()
+
+	recovered := New[string, int](5)
+	if err := recovered.OpenWAL(path, SyncNever); err != nil {
+		t.Fatalf("❌ 恢复失败: %v", err)
+	}
+	defer recovered.Close()
+
+	if keys := recovered.Keys(); len(keys) != 3 || keys[0] != "c" || keys[1] != "a" || keys[2] != "b" {
+		t.Errorf("❌ 恢复后的键应为[c a b], 实际 %v", keys)
+	} else {
+		t.Log("✅ 在快照基础上重放日志，并丢弃不完整的尾部记录")
+	}
+}
+
+// 测试负缓存项替换有效值后恢复不会返回旧值
+func TestWALMissingReplacesValue(t *testing.T) {
+	t.Log("🔍 测试: SetMissing覆盖有效值后恢复")
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := New[string, int](3)
+	if err := cache.OpenWAL(path, SyncNever); err != nil {
+		t.Fatalf("❌ 打开预写日志失败: %v", err)
+	}
+	cache.Set("a", 1)
+	cache.SetMissing("a", time.Hour)
+	cache.Set("b", 2)
+	if err := cache.Close(); err != nil {
+		t.Fatalf("❌ 关闭缓存失败: %v", err)
+	}
+
+	recovered := New[string, int](3)
+	if err := recovered.OpenWAL(path, SyncNever); err != nil {
+		t.Fatalf("❌ 恢复失败: %v", err)
+	}
+	defer recovered.Close()
+
+	if v, ok := recovered.Get("a"); ok {
+		t.Errorf("❌ 被负缓存项覆盖的旧值不应恢复, 实际%v", v)
+	} else {
+		t.Log("✅ 旧值没有重新出现")
+	}
+	if v, ok := recovered.Get("b"); !ok || v != 2 {
+		t.Errorf("❌ 'b'应恢复为2, 实际%v, %v", v, ok)
+	}
+}
+
+// 测试读取改变淘汰顺序后恢复出相同的项，删除不在缓存中的键也会记录
+func TestWALEvictAndDelete(t *testing.T) {
+	t.Log("🔍 测试: 淘汰和删除不在缓存中的键写入日志")
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache := New[string, int](2)
+	if err := cache.OpenWAL(path, SyncNever); err != nil {
+		t.Fatalf("❌ 打开预写日志失败: %v", err)
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3) // 淘汰"b"，重放时没有Get会淘汰"a"
+	cache.Delete("b")
+	if err := cache.Close(); err != nil {
+		t.Fatalf("❌ 关闭缓存失败: %v", err)
+	}
+
+	recovered := New[string, int](2)
+	if err := recovered.OpenWAL(path, SyncNever); err != nil {
+		t.Fatalf("❌ 恢复失败: %v", err)
+	}
+	defer recovered.Close()
+
+	if v, ok := recovered.Get("b"); ok {
+		t.Errorf("❌ 已删除的'b'不应恢复, 实际%v", v)
+	} else {
+		t.Log("✅ 已删除的键没有重新出现")
+	}
+	if v, ok := recovered.Get("a"); !ok || v != 1 {
+		t.Errorf("❌ 'a'应恢复为1, 实际%v, %v", v, ok)
+	} else {
+		t.Log("✅ 恢复出与关闭前相同的项")
+	}
+}
+
+// 测试创建缓存时恢复，重放不触发淘汰回调
+func TestWithWAL(t *testing.T) {
+	t.Log("🔍 测试: WithWAL在创建时恢复且重放不触发回调")
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	cache, err := NewWithOptions[string, int](WithCapacity(3), WithWAL(path, SyncAlways))
+	if err != nil {
+		t.Fatalf("❌ 创建缓存失败: %v", err)
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	if err := cache.Close(); err != nil {
+		t.Fatalf("❌ 关闭缓存失败: %v", err)
+	}
+
+	var evicted []string
+	recovered, err := NewWithOptions[string, int](
+		WithCapacity(1),
+		WithOnEvict(func(key string, _ int, _ time.Time) {
+			evicted = append(evicted, key)
+		}),
+		WithWAL(path, SyncAlways),
+	)
+	if err != nil {
+		t.Fatalf("❌ 恢复失败: %v", err)
+	}
+	defer recovered.Close()
+
+	if v, ok := recovered.Get("c"); !ok || v != 3 {
+		t.Errorf("❌ 'c'应恢复为3, 实际%v, %v", v, ok)
+	} else {
+		t.Log("✅ 创建缓存时已恢复数据")
+	}
+	if len(evicted) != 0 {
+		t.Errorf("❌ 重放不应触发淘汰回调, 实际%v", evicted)
+	} else {
+		t.Log("✅ 重放时淘汰的历史值没有触发回调")
+	}
+
+	recovered.Set("d", 4)
+	if len(evicted) != 1 || evicted[0] != "c" {
+		t.Errorf("❌ 恢复后淘汰回调应正常触发, 实际%v", evicted)
+	}
+}
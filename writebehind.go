@@ -0,0 +1,249 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BatchStore 是支持批量写入的Store
+// 延迟写入模式下，如果绑定的Store实现了该接口，脏项会通过StoreMany一次写入
+type BatchStore[K comparable, V any] interface {
+	Store[K, V]
+	// StoreMany 批量写入键值对
+	StoreMany(values map[K]V) error
+}
+
+// WriteBehind 启用延迟写入模式
+// 参数 batchSize: 脏项达到该数量时立即批量写入，小于等于0时只按时间间隔写入
+// 参数 interval: 定时批量写入的时间间隔，小于等于0时只按数量写入
+// 返回缓存实例本身，支持链式调用
+// 启用后Set只将缓存项标记为脏项，由后台协程批量写入绑定的Store，写入期间不持有缓存锁；
+// 脏项被淘汰、过期或被Clear删除时会立即触发一次批量写入；写入完成前读取该键（包括GetOrLoad）
+// 会将未过期的脏项放回缓存而不是加载，已过期的脏项写入完成前加载到的值不会写入缓存；
+// Close会写入所有剩余脏项
+// 注意: 使用此方法后，不再使用缓存时应调用Close方法，否则未写入的数据会丢失
+func (c *Cache[K, V]) WriteBehind(batchSize int, interval time.Duration) *Cache[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return c
+	}
+
+	// 停止现有的写入协程
+	if c.flushStopCh != nil {
+		close(c.flushStopCh)
+	}
+
+	c.writeBehind = true
+	c.flushSize = batchSize
+	c.flushInterval = interval
+	c.flushStopCh = make(chan struct{})
+	c.flushCh = make(chan struct{}, 1)
+
+	go c.flusherLoop(c.flushStopCh, c.flushCh, interval)
+
+	return c
+}
+
+// Flush 立即将所有脏项写入Store
+// 返回值: 本次写入的错误，以及此前后台写入时尚未报告的错误
+// 写入失败的脏项保持脏状态，会在下次写入时重试；缓存已关闭时返回ErrClosed
+func (c *Cache[K, V]) Flush() error {
+	c.mu.RLock()
+	closed := c.closed
+	c.mu.RUnlock()
+
+	if closed {
+		return ErrClosed
+	}
+
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+	return c.flush()
+}
+
+// flusherLoop 定时、在脏项达到阈值时或有脏项被删除时批量写入
+// 内部使用，作为协程运行
+func (c *Cache[K, V]) flusherLoop(stopCh <-chan struct{}, flushCh <-chan struct{}, interval time.Duration) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-tick:
+		case <-flushCh:
+		case <-stopCh:
+			return
+		}
+
+		c.flushMu.Lock()
+		err := c.flush()
+		c.mu.Lock()
+		c.flushErr = errors.Join(c.flushErr, err)
+		c.mu.Unlock()
+		c.flushMu.Unlock()
+	}
+}
+
+// markDirty 将缓存项标记为脏项，达到批量阈值时通知写入协程
+// 该键被删除后尚未写入的旧脏项不再需要写入
+// 调用前必须持有锁
+func (c *Cache[K, V]) markDirty(key K) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+
+	delete(c.pending, key)
+	item := &e.entry
+	if !item.dirty {
+		item.dirty = true
+		c.dirty++
+	}
+
+	if c.flushSize > 0 && c.dirty >= c.flushSize {
+		c.notifyFlush()
+	}
+}
+
+// notifyFlush 通知写入协程立即写入，写入协程未运行或已被通知时不做任何操作
+// 调用前必须持有锁
+func (c *Cache[K, V]) notifyFlush() {
+	select {
+	case c.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+// flushed 记录一次写入中的节点及其写入次数，用于判断写入期间该项是否被修改
+type flushed[K comparable, V any] struct {
+	node   *node[K, V]
+	writes uint64
+}
+
+// flush 将所有脏项和等待写入的已删除脏项写入Store
+// 返回值: 本次写入的错误，以及此前尚未报告的错误
+// 在持有缓存锁时取出要写入的项，写入Store期间不持有缓存锁，
+// 写入成功后只清除写入期间没有被修改的项的脏标记，被修改的项留到下次写入
+// 调用前必须持有flushMu，不能持有缓存锁
+func (c *Cache[K, V]) flush() error {
+	c.mu.Lock()
+	err := c.flushErr
+	c.flushErr = nil
+
+	if c.dirty == 0 && len(c.pending) == 0 {
+		c.mu.Unlock()
+		return err
+	}
+	store := c.store
+	if store == nil {
+		n := c.dirty
+		c.mu.Unlock()
+		return errors.Join(err, fmt.Errorf("lru: %d dirty entries but no store bound", n))
+	}
+
+	batch := make(map[K]V, c.dirty+len(c.pending))
+	nodes := make(map[K]flushed[K, V], len(batch))
+	for key, e := range c.pending {
+		batch[key] = e.entry.value
+		nodes[key] = flushed[K, V]{node: e, writes: e.writes}
+	}
+	for e := c.list.Front(); e != nil; e = e.Next() {
+		if item := &e.entry; item.dirty {
+			batch[item.key] = item.value
+			nodes[item.key] = flushed[K, V]{node: e, writes: e.writes}
+		}
+	}
+	c.mu.Unlock()
+
+	var failed map[K]struct{}
+	if bs, ok := store.(BatchStore[K, V]); ok {
+		if werr := bs.StoreMany(batch); werr != nil {
+			return errors.Join(err, werr)
+		}
+	} else {
+		for key, value := range batch {
+			if werr := store.Store(key, value); werr != nil {
+				err = errors.Join(err, werr)
+				if failed == nil {
+					failed = make(map[K]struct{})
+				}
+				failed[key] = struct{}{}
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, f := range nodes {
+		if _, ok := failed[key]; ok {
+			continue
+		}
+		if c.pending[key] == f.node {
+			// 该键正在进行的加载可能读到了写入前的旧值，不再写入缓存
+			delete(c.pending, key)
+			c.bump(key)
+		} else if e := c.items[key]; e == f.node && e.writes == f.writes {
+			c.clean(key)
+		}
+	}
+	return err
+}
+
+// queueFlush 将被删除的脏项交给写入协程写入Store
+// 写入前该键的TryGet会返回该脏项的值，没有绑定Store时丢弃该项并记录错误，在下一次Flush或Close时报告
+// 调用前必须持有锁，且e已从缓存中删除
+func (c *Cache[K, V]) queueFlush(e *node[K, V]) {
+	c.dirty--
+	if c.store == nil {
+		c.flushErr = errors.Join(c.flushErr, fmt.Errorf("lru: dirty entry %v dropped, no store bound", e.entry.key))
+		return
+	}
+	if c.pending == nil {
+		c.pending = make(map[K]*node[K, V])
+	}
+	c.pending[e.entry.key] = e
+	c.notifyFlush()
+}
+
+// revive 将已被删除但尚未写入Store的未过期脏项放回缓存，放回后仍为脏项
+// 返回值: 脏项的值和是否找到，缓存被置顶项占满时只返回值而不放回
+// 调用前必须持有锁，且key不在缓存中
+func (c *Cache[K, V]) revive(key K) (V, bool) {
+	e, ok := c.pending[key]
+	if !ok || e.entry.expired(c.now()) {
+		var zero V
+		return zero, false
+	}
+
+	item := e.entry
+	if c.admit(key, false) == nil {
+		delete(c.pending, key)
+		item.pinned = false
+		c.dirty++
+		c.insert(item)
+		c.logOp(item.record(walSet))
+	}
+	return item.value, true
+}
+
+// clean 清除缓存项的脏标记
+// 返回值: 该项清除前是否为脏项
+// 调用前必须持有锁
+func (c *Cache[K, V]) clean(key K) bool {
+	if e, ok := c.items[key]; ok {
+		if item := &e.entry; item.dirty {
+			item.dirty = false
+			c.dirty--
+			return true
+		}
+	}
+	return false
+}
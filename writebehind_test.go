@@ -0,0 +1,242 @@
+package lru
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// batchMemStore 是支持批量写入的测试Store
+type batchMemStore struct {
+	*memStore[string, int]
+	batches []int // 每次StoreMany写入的数量
+}
+
+func (s *batchMemStore) StoreMany(values map[string]int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, len(values))
+	for k, v := range values {
+		s.data[k] = v
+	}
+	return nil
+}
+
+// 测试延迟写入按数量批量写入
+func TestWriteBehindBatchSize(t *testing.T) {
+	t.Log("🔍 测试: 延迟写入按数量批量写入")
+	store := &batchMemStore{memStore: newMemStore[string, int]()}
+	cache := New[string, int](10).Bind(store).WriteBehind(3, 0)
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if _, ok := store.get("a"); ok {
+		t.Fatal("❌ 未达到批量阈值时不应写入Store")
+	}
+
+	cache.Set("c", 3)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := store.get("c"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("❌ 达到批量阈值后应写入Store")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	store.mu.Lock()
+	batches := store.batches
+	store.mu.Unlock()
+	if len(batches) != 1 || batches[0] != 3 {
+		t.Errorf("❌ 应通过一次StoreMany写入3项, 实际 %v", batches)
+	} else {
+		t.Log("✅ 达到阈值后批量写入3项")
+	}
+}
+
+// 测试淘汰脏项时触发写入和Close时全部写入
+func TestWriteBehindEvictAndClose(t *testing.T) {
+	t.Log("🔍 测试: 淘汰脏项时触发写入，Close写入剩余脏项")
+	store := newMemStore[string, int]()
+	cache := New[string, int](2).Bind(store).WriteBehind(0, time.Hour)
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3) // 淘汰脏项"a"，交给写入协程写入
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if v, ok := store.get("a"); ok {
+			if v != 1 {
+				t.Errorf("❌ 被淘汰的脏项写入了错误的值: %v", v)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("❌ 被淘汰的脏项应立即写入Store")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Log("✅ 被淘汰的脏项已写入Store")
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("❌ Close写入失败: %v", err)
+	}
+	for _, k := range []string{"b", "c"} {
+		if _, ok := store.get(k); !ok {
+			t.Errorf("❌ Close后脏项'%s'应已写入Store", k)
+		}
+	}
+	t.Log("✅ Close写入了所有剩余脏项")
+}
+
+// 测试写入错误的报告和重试
+func TestWriteBehindFlushError(t *testing.T) {
+	t.Log("🔍 测试: 延迟写入错误报告和重试")
+	store := newMemStore[string, int]()
+	cache := New[string, int](5).Bind(store).WriteBehind(0, time.Hour)
+	defer cache.Close()
+
+	cache.Set("a", 1)
+
+	errWrite := errors.New("write failed")
+	store.failNext = errWrite
+	if err := cache.Flush(); !errors.Is(err, errWrite) {
+		t.Fatalf("❌ Flush应报告写入错误, 实际 %v", err)
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("❌ 重试写入失败: %v", err)
+	}
+	if v, ok := store.get("a"); !ok || v != 1 {
+		t.Errorf("❌ 失败的脏项应在下次Flush时重试: %v, %v", v, ok)
+	} else {
+		t.Log("✅ 写入失败的脏项在下次Flush时重试成功")
+	}
+}
+
+// 测试被淘汰的脏项写入Store之前读穿透返回该脏项的值
+func TestWriteBehindPendingRead(t *testing.T) {
+	t.Log("🔍 测试: 尚未写入的被淘汰脏项优先于Store中的旧值")
+	store := newMemStore[string, int]()
+	store.data["a"] = 0
+	cache := New[string, int](2).Bind(store).WriteBehind(0, time.Hour)
+	defer cache.Close()
+
+	errWrite := errors.New("write failed")
+	store.mu.Lock()
+	store.failNext = errWrite
+	store.mu.Unlock()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3) // 淘汰脏项"a"，写入失败后等待重试
+
+	if v, err := cache.TryGet("a"); err != nil || v != 1 {
+		t.Errorf("❌ 应返回尚未写入的脏项的值1, 实际 %v, %v", v, err)
+	} else {
+		t.Log("✅ 没有从Store加载旧值")
+	}
+	// 失败的写入可能由写入协程或Flush执行，错误都由这一次Flush报告
+	if err := cache.Flush(); !errors.Is(err, errWrite) {
+		t.Errorf("❌ Flush应报告写入错误, 实际 %v", err)
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("❌ 重试写入失败: %v", err)
+	}
+	if v, ok := store.get("a"); !ok || v != 1 {
+		t.Errorf("❌ 重试后Store应写入1: %v, %v", v, ok)
+	}
+}
+
+// 测试写入Store期间不持有缓存锁，写入期间被修改的项保持脏状态
+func TestWriteBehindFlushUnlocked(t *testing.T) {
+	t.Log("🔍 测试: 写入Store期间可以读写缓存")
+	store := &hookStore{memStore: newMemStore[string, int]()}
+	cache := New[string, int](5).Bind(store).WriteBehind(0, time.Hour)
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	store.onStore = func() {
+		if v, ok := cache.Get("a"); !ok || v != 1 {
+			t.Errorf("❌ 写入期间应能读取缓存: %v, %v", v, ok)
+		}
+		cache.Set("a", 2)
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("❌ Flush失败: %v", err)
+	}
+	if v, _ := store.get("a"); v != 1 {
+		t.Fatalf("❌ 第一次写入应为1, 实际 %v", v)
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("❌ Flush失败: %v", err)
+	}
+	if v, _ := store.get("a"); v != 2 {
+		t.Errorf("❌ 写入期间被修改的项应在下次写入, 实际 %v", v)
+	} else {
+		t.Log("✅ 写入期间的修改没有被清除脏标记")
+	}
+}
+
+// 测试被淘汰的脏项写入Store之前GetOrLoad不会缓存Store中的旧值
+func TestWriteBehindPendingLoad(t *testing.T) {
+	t.Log("🔍 测试: 等待写入的脏项优先于加载函数读取的旧值")
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	store := newMemStore[string, int]()
+	for _, key := range []string{"a", "b", "x", "y"} {
+		store.data[key] = 0
+	}
+	cache := New[string, int](1).Bind(store).WriteBehind(0, time.Hour)
+	cache.now = clock.Now
+	defer cache.Close()
+
+	calls := 0
+	loader := func(key string) (int, error) {
+		calls++
+		return store.Load(key)
+	}
+	// evict 使key的写入一直失败，并以加载的干净项淘汰唯一的脏项key
+	errWrite := errors.New("write failed")
+	evict := func(key, by string) {
+		store.mu.Lock()
+		store.failKeys = map[string]error{key: errWrite}
+		store.mu.Unlock()
+		cache.GetOrLoad(by, loader)
+		cache.Flush()
+		calls = 0
+	}
+
+	cache.Set("a", 1)
+	evict("a", "x")
+	if v, err := cache.GetOrLoad("a", loader); err != nil || v != 1 || calls != 0 {
+		t.Errorf("❌ 应返回等待写入的值1且不调用加载函数: %v, %v, 加载%d次", v, err, calls)
+	} else {
+		t.Log("✅ 等待写入的脏项被放回缓存")
+	}
+	store.mu.Lock()
+	store.failKeys = nil
+	store.mu.Unlock()
+	cache.Flush() // 可能报告写入协程此前的写入失败
+	if v, ok := store.get("a"); !ok || v != 1 {
+		t.Errorf("❌ 放回的脏项应写入Store: %v, %v", v, ok)
+	}
+	if v, ok := cache.Peek("a"); !ok || v != 1 {
+		t.Errorf("❌ 缓存应与Store一致: %v, %v", v, ok)
+	}
+
+	// 已过期的脏项不再返回，但写入Store之前也不缓存加载到的旧值
+	cache.SetWithTTL("b", 2, time.Second)
+	evict("b", "y")
+	clock.Advance(2 * time.Second)
+	if v, err := cache.GetOrLoad("b", loader); err != nil || v != 0 {
+		t.Errorf("❌ 已过期的脏项应从加载函数读取: %v, %v", v, err)
+	}
+	if _, ok := cache.Peek("b"); ok {
+		t.Error("❌ 脏项写入Store之前不应缓存加载到的旧值")
+	} else {
+		t.Log("✅ 加载到的旧值没有写入缓存")
+	}
+}